@@ -149,6 +149,32 @@ func main() {
 		}
 	})
 
+	// Replay endpoint - returns the last recorded match as frames for the overlay replay mode
+	http.HandleFunc("/replay", func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("token")
+		if token == "" {
+			http.Error(w, "missing token", http.StatusBadRequest)
+			return
+		}
+
+		roomID, ok := handler.ResolveStreamToken(token)
+		if !ok {
+			http.Error(w, "invalid or expired token", http.StatusUnauthorized)
+			return
+		}
+
+		replay := handler.GetReplay(roomID)
+		if replay == nil {
+			http.Error(w, "nothing to replay", http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Write(replay)
+	})
+
 	addr := fmt.Sprintf(":%d", *port)
 	log.Printf("Starting BingoSync WebSocket server on %s", addr)
 	log.Printf("Data directory: %s", *dataDir)
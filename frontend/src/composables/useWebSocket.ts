@@ -144,8 +144,8 @@ export function useWebSocket() {
     send('list_rooms');
   }
 
-  function setRole(targetUserId: string, role: string, playerColor?: string) {
-    send('set_role', { target_user_id: targetUserId, role, player_color: playerColor });
+  function setRole(targetUserId: string, role: string, team?: string) {
+    send('set_role', { target_user_id: targetUserId, role, team });
   }
 
   function setPassword(password: string) {
@@ -157,20 +157,24 @@ export function useWebSocket() {
     send('set_rule', { rule, phase_config: phaseConfig });
   }
 
+  function setBoardSize(size: number) {
+    send('set_board_size', { size });
+  }
+
   function startGame() {
     send('start_game');
   }
 
-  function markCell(row: number, col: number, color: string) {
-    send('mark_cell', { row, col, color });
+  function markCell(row: number, col: number, team: string) {
+    send('mark_cell', { row, col, team });
   }
 
   function unmarkCell(row: number, col: number) {
     send('unmark_cell', { row, col });
   }
 
-  function clearCellMark(row: number, col: number, color: string) {
-    send('clear_cell_mark', { row, col, color });
+  function clearCellMark(row: number, col: number, team: string) {
+    send('clear_cell_mark', { row, col, team });
   }
 
   function resetGame() {
@@ -189,8 +193,8 @@ export function useWebSocket() {
     send('set_cell_text', { texts });
   }
 
-  function settle(player: string) {
-    send('settle', { player });
+  function settle(team: string) {
+    send('settle', { team });
   }
 
   function createStreamToken() {
@@ -208,6 +212,7 @@ export function useWebSocket() {
     setRole,
     setPassword,
     setRule,
+    setBoardSize,
     startGame,
     markCell,
     unmarkCell,
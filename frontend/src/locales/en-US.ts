@@ -41,15 +41,11 @@ export default {
     restart: 'Restart',
     importText: 'Import Text',
     exportText: 'Export Text',
-    redTeam: 'Red',
-    blueTeam: 'Blue',
     draw: 'Draw',
     winner: 'Wins',
     redScore: 'Red',
     blueScore: 'Blue',
     selectColor: 'Select Color',
-    red: 'Red',
-    blue: 'Blue',
     clear: 'Clear',
     streamerMode: 'Streamer Mode',
     exitStreamerMode: 'Exit Streamer Mode',
@@ -64,8 +60,7 @@ export default {
     unassigned: 'Unassigned',
     you: 'you',
     yourRole: 'Your Role',
-    becomeRed: 'Join Red',
-    becomeBlue: 'Join Blue',
+    join: 'Join',
     becomeReferee: 'Become Referee',
     becomeSpectator: 'Become Spectator',
     remove: 'Remove',
@@ -100,8 +95,9 @@ export default {
     show: 'Show',
     hide: 'Hide',
     applySettings: 'Apply Settings',
+    boardSize: 'Board Size',
     cellText: 'Cell Text',
-    importHint: 'Supports .txt (one per line) or .csv (5 per line)',
+    importHint: 'Supports .txt (one per line) or .csv (one board row per line)',
     importFailed: 'File import failed',
     phaseConfig: {
       rowScores: 'Row Scores (A)',
@@ -41,15 +41,11 @@ export default {
     restart: '重新开始',
     importText: '导入文字',
     exportText: '导出文字',
-    redTeam: '红方',
-    blueTeam: '蓝方',
     draw: '平局',
     winner: '获胜',
     redScore: '红方',
     blueScore: '蓝方',
     selectColor: '选择颜色',
-    red: '红',
-    blue: '蓝',
     clear: '清除',
     streamerMode: '直播模式',
     exitStreamerMode: '退出直播模式',
@@ -64,8 +60,7 @@ export default {
     unassigned: '未分配',
     you: '你',
     yourRole: '你的身份',
-    becomeRed: '成为红方',
-    becomeBlue: '成为蓝方',
+    join: '加入',
     becomeReferee: '成为裁判',
     becomeSpectator: '成为观众',
     remove: '移除',
@@ -100,8 +95,9 @@ export default {
     show: '显示',
     hide: '隐藏',
     applySettings: '应用设置',
+    boardSize: '棋盘大小',
     cellText: '格子文字',
-    importHint: '支持 .txt (每行一个) 或 .csv (每行5个)',
+    importHint: '支持 .txt (每行一个) 或 .csv (每行对应棋盘一行)',
     importFailed: '文件导入失败',
     phaseConfig: {
       rowScores: '每行积分 (A)',
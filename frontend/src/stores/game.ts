@@ -1,6 +1,6 @@
 import { defineStore } from 'pinia';
 import { ref, computed } from 'vue';
-import type { Game, Room, User, RoomInfo, StateUpdate } from '../types';
+import type { Game, Room, User, RoomInfo, StateUpdate, TeamID } from '../types';
 
 export const useGameStore = defineStore('game', () => {
   // State
@@ -26,11 +26,24 @@ export const useGameStore = defineStore('game', () => {
   const isSpectator = computed(() => currentUser.value?.role === 'spectator');
   const inRoom = computed(() => currentRoom.value !== null);
   
-  const redPlayer = computed(() => users.value.find(u => u.player_color === 'red'));
-  const bluePlayer = computed(() => users.value.find(u => u.player_color === 'blue'));
+  const teams = computed(() => game.value?.teams ?? []);
+  // Team the current user plays for, 'none' unless they are a player
+  const currentTeam = computed<TeamID>(() => (isPlayer.value && currentUser.value?.team) || 'none');
   const spectators = computed(() => users.value.filter(u => u.role === 'spectator'));
 
   // Actions
+  function playersOf(team: TeamID) {
+    return users.value.filter(u => u.role === 'player' && u.team === team);
+  }
+
+  function teamById(team: TeamID) {
+    return teams.value.find(t => t.id === team);
+  }
+
+  function teamState(team: TeamID) {
+    return game.value?.team_states?.find(s => s.team === team);
+  }
+
   function setConnected(value: boolean) {
     connected.value = value;
   }
@@ -103,10 +116,13 @@ export const useGameStore = defineStore('game', () => {
     isPlayer,
     isSpectator,
     inRoom,
-    redPlayer,
-    bluePlayer,
+    teams,
+    currentTeam,
     spectators,
     // Actions
+    playersOf,
+    teamById,
+    teamState,
     setConnected,
     setUserInfo,
     setStateUpdate,
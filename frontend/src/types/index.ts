@@ -1,22 +1,37 @@
 // Types for BingoSync
 
 // Protocol version - must match server's ProtocolVersion
-export const PROTOCOL_VERSION = 1;
+export const PROTOCOL_VERSION = 2;
 
-export type GameRule = 'normal' | 'blackout' | 'phase';
+export type GameRule = 'normal' | 'blackout' | 'phase' | 'points';
 export type GameStatus = 'waiting' | 'playing' | 'finished';
-export type PlayerColor = 'none' | 'red' | 'blue';
+// Team ID from the room's team list, 'none' for no team
+export type TeamID = string;
 export type UserRole = 'spectator' | 'player' | 'referee';
-export type WinReason = 'bingo' | 'full_board' | 'blackout' | 'phase';
+export type WinReason = 'bingo' | 'full_board' | 'blackout' | 'phase' | 'timeout' | 'points';
+
+export interface Team {
+  id: TeamID;
+  name: string;
+  color: string;
+}
+
+export interface Mark {
+  team: TeamID;
+}
 
 export interface Cell {
-  marked_by: PlayerColor;
-  second_mark?: PlayerColor;
+  marked_by: TeamID;
+  second_mark?: TeamID;
+  marks?: Mark[];
   times: number;
   text: string;
+  lock?: 'locked' | 'void';
+  points?: number;
 }
 
 export interface Board {
+  size: number;
   cells: Cell[][];
 }
 
@@ -27,13 +42,34 @@ export interface PhaseConfig {
   unlock_threshold: number;
   bingo_bonus: number;
   final_bonus: number;
+  phases?: Phase[];
+}
+
+// Phase made of any group of cells, replacing one phase per row when set
+export interface Phase {
+  cells: [number, number][];
+  score: number;
+  later_score: number;
+  limit: number;
+  unlock_threshold: number;
+}
+
+export interface TeamScore {
+  team: TeamID;
+  score: number;
 }
 
 export interface Winner {
-  winner: PlayerColor;
+  winner: TeamID;
   reason: WinReason;
-  red_score: number;
-  blue_score: number;
+  scores: TeamScore[];
+}
+
+export interface TeamState {
+  team: TeamID;
+  row_marks: number[];
+  unlocked_row: number;
+  settled: boolean;
 }
 
 export interface Game {
@@ -42,22 +78,18 @@ export interface Game {
   phase_config?: PhaseConfig;
   status: GameStatus;
   winner?: Winner;
-  red_row_marks?: number[];
-  blue_row_marks?: number[];
-  red_unlocked_row?: number;
-  blue_unlocked_row?: number;
-  bingo_achiever?: PlayerColor;
+  teams: Team[];
+  team_states?: TeamState[];
+  bingo_achiever?: TeamID;
   bingo_line?: number;
-  red_settled?: boolean;
-  blue_settled?: boolean;
-  first_settler?: PlayerColor;
+  first_settler?: TeamID;
 }
 
 export interface User {
   id: string;
   name: string;
   role: UserRole;
-  player_color: PlayerColor;
+  team: TeamID;
 }
 
 export interface Room {
@@ -103,6 +135,7 @@ export type MessageType =
   | 'reset_game'
   | 'set_cell_text'
   | 'settle'
+  | 'set_board_size'
   | 'create_stream_token'
   | 'stream_token'
   | 'state_update'
@@ -0,0 +1,84 @@
+package game
+
+import (
+	"errors"
+	"slices"
+)
+
+var (
+	ErrClaimPending  = errors.New("team already has a pending claim on this cell")
+	ErrClaimNotFound = errors.New("no pending claim for this team on this cell")
+)
+
+// SetVerifyClaims turns claim verification on or off (not while playing)
+// With verification on, player marks are pending claims until a referee confirms them
+func (g *Game) SetVerifyClaims(verify bool) error {
+	if g.Status == StatusPlaying || g.Status == StatusCountdown {
+		return errors.New("cannot change claim verification while playing")
+	}
+	g.VerifyClaims = verify
+	return nil
+}
+
+// ClaimCell records a pending claim for mark.Team on a cell
+// Claims the rule would refuse as a mark right now are rejected at once,
+// the mark is checked again when the claim is confirmed
+func (g *Game) ClaimCell(row, col int, mark Mark) error {
+	if err := g.checkMark(row, col, mark.Team); err != nil {
+		return err
+	}
+
+	cell := &g.Board.Cells[row][col]
+	if cell.claimIndex(mark.Team) >= 0 {
+		return ErrClaimPending
+	}
+	if err := g.rule().Mark(g.Clone(), row, col, mark); err != nil {
+		return err
+	}
+
+	cell.Claims = append(cell.Claims, mark)
+	return nil
+}
+
+// ConfirmClaim turns a team's pending claim into a mark, checking for a win
+// The claim stays pending if the mark is no longer allowed
+func (g *Game) ConfirmClaim(row, col int, team TeamID) error {
+	if !g.Board.InBounds(row, col) {
+		return ErrInvalidPosition
+	}
+
+	cell := &g.Board.Cells[row][col]
+	i := cell.claimIndex(team)
+	if i < 0 {
+		return ErrClaimNotFound
+	}
+	if err := g.MarkCellBy(row, col, cell.Claims[i]); err != nil {
+		return err
+	}
+
+	cell.Claims = slices.Delete(cell.Claims, i, i+1)
+	return nil
+}
+
+// RejectClaim drops a team's pending claim without marking the cell
+func (g *Game) RejectClaim(row, col int, team TeamID) error {
+	if !g.Board.InBounds(row, col) {
+		return ErrInvalidPosition
+	}
+
+	cell := &g.Board.Cells[row][col]
+	i := cell.claimIndex(team)
+	if i < 0 {
+		return ErrClaimNotFound
+	}
+
+	cell.Claims = slices.Delete(cell.Claims, i, i+1)
+	return nil
+}
+
+// claimIndex returns the index of the team's pending claim, or -1
+func (c *Cell) claimIndex(team TeamID) int {
+	return slices.IndexFunc(c.Claims, func(m Mark) bool {
+		return m.Team == team
+	})
+}
@@ -0,0 +1,99 @@
+package game
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestClaimVerification(t *testing.T) {
+	g := NewGameWithSize(RuleNormal, 3)
+	if err := g.SetVerifyClaims(true); err != nil {
+		t.Fatalf("SetVerifyClaims failed: %v", err)
+	}
+	g.Start()
+
+	alice := Mark{Team: TeamRed, PlayerID: "u1", PlayerName: "alice"}
+	for col := 0; col < 3; col++ {
+		if err := g.ClaimCell(0, col, alice); err != nil {
+			t.Fatalf("ClaimCell failed: %v", err)
+		}
+	}
+	if err := g.ClaimCell(0, 0, alice); err != ErrClaimPending {
+		t.Errorf("Second claim should fail, got: %v", err)
+	}
+
+	// Pending claims are no marks
+	if g.Status != StatusPlaying || g.Board.Cells[0][0].MarkedBy() != TeamNone {
+		t.Fatal("Claims should not count before they are confirmed")
+	}
+
+	g.ConfirmClaim(0, 0, TeamRed)
+	g.RejectClaim(0, 1, TeamRed)
+	g.ConfirmClaim(0, 2, TeamRed)
+	if g.Status != StatusPlaying {
+		t.Fatal("Rejected claim should not complete the row")
+	}
+	if m := g.Board.Cells[0][0].Marks[0]; m.PlayerName != "alice" || len(g.Board.Cells[0][0].Claims) != 0 {
+		t.Errorf("Confirmed mark should keep the claimer, got %+v", g.Board.Cells[0][0])
+	}
+	if err := g.ConfirmClaim(0, 1, TeamRed); err != ErrClaimNotFound {
+		t.Errorf("Rejected claim should be gone, got: %v", err)
+	}
+
+	g.ClaimCell(0, 1, alice)
+	g.ConfirmClaim(0, 1, TeamRed)
+	if g.Winner == nil || g.Winner.Winner != TeamRed {
+		t.Errorf("Confirmed row should win, got: %+v", g.Winner)
+	}
+}
+
+func TestClaimChecksRule(t *testing.T) {
+	g := NewGameWithSize(RuleNormal, 3)
+	g.Start()
+	g.MarkCell(1, 1, TeamBlue)
+	if err := g.ClaimCell(1, 1, Mark{Team: TeamRed}); err != ErrCellAlreadyMarked {
+		t.Errorf("Claim on a taken cell should fail, got: %v", err)
+	}
+
+	// Both teams claim a cell, the first confirmed one takes it
+	g.ClaimCell(2, 2, Mark{Team: TeamRed})
+	g.ClaimCell(2, 2, Mark{Team: TeamBlue})
+	g.ConfirmClaim(2, 2, TeamBlue)
+	if err := g.ConfirmClaim(2, 2, TeamRed); err != ErrCellAlreadyMarked {
+		t.Errorf("Claim on a cell taken since should fail, got: %v", err)
+	}
+	if len(g.Board.Cells[2][2].Claims) != 1 {
+		t.Error("Failed confirmation should leave the claim for the referee to reject")
+	}
+
+	p := NewGame(RulePhase)
+	p.Start()
+	if err := p.ClaimCell(1, 0, Mark{Team: TeamRed}); !errors.Is(err, ErrRowLocked) {
+		t.Errorf("Claim on a locked row should fail, got: %v", err)
+	}
+}
+
+func TestReplayClaims(t *testing.T) {
+	g := NewGameWithSize(RuleNormal, 3)
+	g.SetVerifyClaims(true)
+	g.Start()
+
+	var log EventLog
+	log.Append(Event{Action: ActionStart, Game: g.Clone()})
+	log.Append(Event{Action: ActionClaim, ActorID: "u1", ActorName: "alice", Row: 0, Col: 0, Team: TeamRed})
+	log.Append(Event{Action: ActionClaim, ActorID: "u2", ActorName: "bob", Row: 1, Col: 1, Team: TeamBlue})
+	log.Append(Event{Action: ActionConfirmClaim, ActorID: "ref", ActorName: "referee", Row: 0, Col: 0, Team: TeamRed})
+	log.Append(Event{Action: ActionRejectClaim, ActorID: "ref", ActorName: "referee", Row: 1, Col: 1, Team: TeamBlue})
+
+	replayed, err := ReplayToSeq(log, log.LastSeq())
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	cell := replayed.Board.Cells[0][0]
+	if cell.MarkedBy() != TeamRed || cell.Marks[0].PlayerName != "alice" {
+		t.Errorf("Confirmed claim should be alice's mark, got %+v", cell)
+	}
+	if len(replayed.Board.Cells[1][1].Claims) != 0 || replayed.Board.Cells[1][1].MarkedBy() != TeamNone {
+		t.Errorf("Rejected claim should leave the cell empty, got %+v", replayed.Board.Cells[1][1])
+	}
+}
@@ -0,0 +1,88 @@
+package game
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	MaxTimeLimit = 24 * time.Hour // Longest time limit a game can have
+	MaxCountdown = time.Minute    // Longest start countdown
+)
+
+var (
+	ErrInvalidTimeLimit = errors.New("time limit must be between 0 and 24 hours")
+	ErrInvalidCountdown = errors.New("countdown must be between 0 and 60 seconds")
+)
+
+// SetTimeLimit sets the game's time limit, 0 removes it (not while playing)
+func (g *Game) SetTimeLimit(limit time.Duration) error {
+	if g.Status == StatusPlaying || g.Status == StatusCountdown {
+		return errors.New("cannot change time limit while playing")
+	}
+	if limit < 0 || limit > MaxTimeLimit {
+		return ErrInvalidTimeLimit
+	}
+	g.TimeLimit = limit
+	return nil
+}
+
+// SetCountdown sets the delay between starting and revealing the game, 0 starts at once
+func (g *Game) SetCountdown(countdown time.Duration) error {
+	if g.Status == StatusPlaying || g.Status == StatusCountdown {
+		return errors.New("cannot change countdown while playing")
+	}
+	if countdown < 0 || countdown > MaxCountdown {
+		return ErrInvalidCountdown
+	}
+	g.Countdown = countdown
+	return nil
+}
+
+// RevealTime returns when a game in countdown is revealed
+func (g *Game) RevealTime() (time.Time, bool) {
+	if g.Status != StatusCountdown {
+		return time.Time{}, false
+	}
+	return g.StartTime, true
+}
+
+// Deadline returns when the time limit runs out, ok is false if the game has no running limit
+func (g *Game) Deadline() (deadline time.Time, ok bool) {
+	if g.TimeLimit <= 0 || g.StartTime.IsZero() {
+		return time.Time{}, false
+	}
+	return g.StartTime.Add(g.TimeLimit), true
+}
+
+// TimeUp reports whether a playing game has run out of time at now
+func (g *Game) TimeUp(now time.Time) bool {
+	deadline, ok := g.Deadline()
+	return ok && g.Status == StatusPlaying && !now.Before(deadline)
+}
+
+// UpdateClock brings the game up to time now: reveals it once the countdown is over,
+// ends it by timeout once the limit has run out, and records EndTime when the game
+// finished (or clears it when the game was reopened)
+// Returns true if the game ended by timeout
+func (g *Game) UpdateClock(now time.Time) bool {
+	if g.Status == StatusCountdown && !now.Before(g.StartTime) {
+		g.Status = StatusPlaying
+	}
+
+	if g.TimeUp(now) {
+		deadline, _ := g.Deadline()
+		g.Winner = g.decide(WinReasonTimeout)
+		g.Status = StatusFinished
+		g.EndTime = deadline
+		return true
+	}
+
+	switch {
+	case g.Status == StatusFinished && g.EndTime.IsZero():
+		g.EndTime = now
+	case g.Status != StatusFinished:
+		g.EndTime = time.Time{}
+	}
+	return false
+}
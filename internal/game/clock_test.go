@@ -0,0 +1,144 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+var clockStart = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func TestTimeoutNormalDecidedByMarkCount(t *testing.T) {
+	g := NewGame(RuleNormal)
+	g.SetTimeLimit(10 * time.Minute)
+	g.StartAt(clockStart)
+	g.MarkCell(0, 0, TeamRed)
+	g.MarkCell(0, 1, TeamRed)
+	g.MarkCell(1, 0, TeamBlue)
+
+	if g.UpdateClock(clockStart.Add(9 * time.Minute)) {
+		t.Fatal("Game should not time out before the limit")
+	}
+	if !g.UpdateClock(clockStart.Add(10 * time.Minute)) {
+		t.Fatal("Game should time out at the limit")
+	}
+
+	if g.Status != StatusFinished || g.Winner.Reason != WinReasonTimeout || g.Winner.Winner != TeamRed {
+		t.Errorf("Red should win by mark count on timeout, got: %+v", g.Winner)
+	}
+	if g.Winner.Scores[TeamRed] != 2 || g.Winner.Scores[TeamBlue] != 1 {
+		t.Errorf("Timeout scores should be mark counts, got: %v", g.Winner.Scores)
+	}
+	if !g.EndTime.Equal(clockStart.Add(10 * time.Minute)) {
+		t.Errorf("EndTime should be the deadline, got: %v", g.EndTime)
+	}
+	if g.UpdateClock(clockStart.Add(11 * time.Minute)) {
+		t.Error("A finished game should not time out again")
+	}
+}
+
+func TestTimeoutPhaseDecidedByScore(t *testing.T) {
+	g := NewGame(RulePhase)
+	g.SetTimeLimit(time.Minute)
+	g.StartAt(clockStart)
+	g.MarkCell(0, 0, TeamRed)
+	g.MarkCell(0, 0, TeamBlue)
+	g.MarkCell(0, 1, TeamBlue)
+
+	g.UpdateClock(clockStart.Add(time.Hour))
+
+	scores := g.CalculatePhaseScore()
+	if g.Winner == nil || g.Winner.Reason != WinReasonTimeout || g.Winner.Winner != TeamBlue {
+		t.Errorf("Blue should win by phase score on timeout, got: %+v", g.Winner)
+	}
+	if g.Winner.Scores[TeamBlue] != scores[TeamBlue] {
+		t.Errorf("Timeout scores should be phase scores, got: %v want %v", g.Winner.Scores, scores)
+	}
+}
+
+func TestClockWithoutLimit(t *testing.T) {
+	g := NewGame(RuleNormal)
+	g.StartAt(clockStart)
+
+	if _, ok := g.Deadline(); ok {
+		t.Error("Game without a limit should have no deadline")
+	}
+	if g.UpdateClock(clockStart.Add(48 * time.Hour)) {
+		t.Error("Game without a limit should never time out")
+	}
+
+	for col := 0; col < g.Board.Size(); col++ {
+		g.MarkCell(0, col, TeamRed)
+	}
+	g.UpdateClock(clockStart.Add(time.Minute))
+	if !g.EndTime.Equal(clockStart.Add(time.Minute)) {
+		t.Errorf("EndTime should be recorded when the game finishes, got: %v", g.EndTime)
+	}
+
+	g.UnmarkCell(0, 0)
+	g.UpdateClock(clockStart.Add(2 * time.Minute))
+	if !g.EndTime.IsZero() {
+		t.Errorf("EndTime should be cleared when the game is reopened, got: %v", g.EndTime)
+	}
+}
+
+func TestSetTimeLimitAndRule(t *testing.T) {
+	g := NewGame(RuleNormal)
+	if err := g.SetTimeLimit(-time.Second); err != ErrInvalidTimeLimit {
+		t.Errorf("Negative time limit should fail, got: %v", err)
+	}
+	g.SetTeams(threeTeams())
+	g.SetTimeLimit(30 * time.Minute)
+
+	if err := g.SetRule(RulePhase, DefaultPhaseConfig(DefaultBoardSize)); err != nil {
+		t.Fatalf("SetRule failed: %v", err)
+	}
+	if g.Rule != RulePhase || len(g.Teams) != 3 || g.TimeLimit != 30*time.Minute {
+		t.Errorf("SetRule should keep teams and time limit, got: %v %d %v", g.Rule, len(g.Teams), g.TimeLimit)
+	}
+
+	g.Start()
+	if err := g.SetTimeLimit(time.Minute); err == nil {
+		t.Error("Time limit should not change while playing")
+	}
+}
+
+func TestCountdownStart(t *testing.T) {
+	g := NewGame(RuleNormal)
+	g.SetCountdown(10 * time.Second)
+	g.SetTimeLimit(time.Minute)
+	g.StartAt(clockStart)
+
+	if g.Status != StatusCountdown || g.Started() {
+		t.Fatalf("Game should be in countdown, got: %v", g.Status)
+	}
+	if reveal, ok := g.RevealTime(); !ok || !reveal.Equal(clockStart.Add(10*time.Second)) {
+		t.Errorf("Reveal time should be start plus countdown, got: %v", reveal)
+	}
+	if err := g.MarkCell(0, 0, TeamRed); err != ErrGameNotStarted {
+		t.Errorf("Marks should be rejected during countdown, got: %v", err)
+	}
+	if err := g.Start(); err == nil {
+		t.Error("Game in countdown should not start again")
+	}
+
+	g.UpdateClock(clockStart.Add(9 * time.Second))
+	if g.Status != StatusCountdown {
+		t.Errorf("Game should still be in countdown, got: %v", g.Status)
+	}
+
+	g.UpdateClock(clockStart.Add(10 * time.Second))
+	if g.Status != StatusPlaying {
+		t.Fatalf("Game should be playing at time zero, got: %v", g.Status)
+	}
+	if err := g.MarkCell(0, 0, TeamRed); err != nil {
+		t.Errorf("Marks should be accepted after the countdown, got: %v", err)
+	}
+
+	if deadline, _ := g.Deadline(); !deadline.Equal(clockStart.Add(70 * time.Second)) {
+		t.Errorf("Time limit should start at the reveal, got deadline: %v", deadline)
+	}
+
+	if err := g.SetCountdown(2 * time.Minute); err == nil {
+		t.Error("Countdown should not change while playing")
+	}
+}
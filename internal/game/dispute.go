@@ -0,0 +1,167 @@
+package game
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxDisputeReason caps the length of a dispute reason in characters
+const MaxDisputeReason = 200
+
+// DisputeStatus is the state of a dispute
+type DisputeStatus string
+
+const (
+	DisputeOpen     DisputeStatus = "open"
+	DisputeUpheld   DisputeStatus = "upheld"   // The referee kept the mark
+	DisputeReverted DisputeStatus = "reverted" // The referee removed the mark
+	DisputeClosed   DisputeStatus = "closed"   // The mark went away without a ruling, by an undo or a reset
+)
+
+var (
+	ErrInvalidDispute  = errors.New("invalid dispute")
+	ErrNoMarkToDispute = errors.New("team has no mark on this cell")
+	ErrDisputeOpen     = errors.New("this mark is already disputed")
+	ErrDisputeNotFound = errors.New("dispute not found")
+	ErrDisputeResolved = errors.New("dispute is already resolved")
+)
+
+// Dispute is a team's challenge of another team's mark, resolved by the referee
+type Dispute struct {
+	ID         int           `json:"id"`
+	Time       time.Time     `json:"time"`
+	Row        int           `json:"row"`
+	Col        int           `json:"col"`
+	Team       TeamID        `json:"team"`        // Team whose mark is disputed
+	By         TeamID        `json:"by"`          // Team raising the dispute
+	PlayerID   string        `json:"player_id"`   // Player raising the dispute
+	PlayerName string        `json:"player_name"` // Name at the time, users are not persisted
+	Reason     string        `json:"reason"`
+	Status     DisputeStatus `json:"status"`
+
+	// Set once resolved
+	ResolverName string    `json:"resolver_name,omitempty"`
+	ResolvedAt   time.Time `json:"resolved_at"`
+}
+
+// Disputes is a room's list of disputes in the order they were raised
+type Disputes []Dispute
+
+// Raise validates a dispute of the current game's board and adds it as open
+// Team defaults to the cell's first marker
+func (l *Disputes) Raise(g *Game, d Dispute) (Dispute, error) {
+	if !g.Started() {
+		return Dispute{}, ErrGameNotStarted
+	}
+	if !g.Board.InBounds(d.Row, d.Col) {
+		return Dispute{}, ErrInvalidPosition
+	}
+
+	cell := &g.Board.Cells[d.Row][d.Col]
+	if d.Team == TeamNone {
+		d.Team = cell.MarkedBy()
+	}
+	if d.Team == d.By {
+		return Dispute{}, errors.New("cannot dispute your own team's mark")
+	}
+	if !cell.HasMark(d.Team) {
+		return Dispute{}, ErrNoMarkToDispute
+	}
+
+	d.Reason = strings.TrimSpace(d.Reason)
+	if d.Reason == "" {
+		return Dispute{}, errors.New("dispute needs a reason")
+	}
+	if utf8.RuneCountInString(d.Reason) > MaxDisputeReason {
+		return Dispute{}, ErrInvalidDispute
+	}
+
+	for _, other := range *l {
+		if other.Status == DisputeOpen && other.Row == d.Row && other.Col == d.Col && other.Team == d.Team {
+			return Dispute{}, ErrDisputeOpen
+		}
+	}
+
+	d.ID = len(*l) + 1
+	d.Status = DisputeOpen
+	d.ResolverName = ""
+	d.ResolvedAt = time.Time{}
+	*l = append(*l, d)
+	return d, nil
+}
+
+// Open returns the disputes waiting for the referee, oldest first
+func (l Disputes) Open() []Dispute {
+	var open []Dispute
+	for _, d := range l {
+		if d.Status == DisputeOpen {
+			open = append(open, d)
+		}
+	}
+	return open
+}
+
+// CloseGone closes the open disputes whose mark is no longer on the game's board,
+// which could otherwise never be reverted
+func (l Disputes) CloseGone(g *Game, now time.Time) {
+	for i := range l {
+		d := &l[i]
+		if d.Status != DisputeOpen {
+			continue
+		}
+		if !g.Board.InBounds(d.Row, d.Col) || !g.Board.Cells[d.Row][d.Col].HasMark(d.Team) {
+			d.Status = DisputeClosed
+			d.ResolvedAt = now
+		}
+	}
+}
+
+// Find returns an open dispute by ID
+func (l Disputes) Find(id int) (*Dispute, error) {
+	for i := range l {
+		if l[i].ID != id {
+			continue
+		}
+		if l[i].Status != DisputeOpen {
+			return nil, ErrDisputeResolved
+		}
+		return &l[i], nil
+	}
+	return nil, ErrDisputeNotFound
+}
+
+// Resolve closes the dispute as upheld or reverted
+func (d *Dispute) Resolve(status DisputeStatus, resolverName string, now time.Time) error {
+	if status != DisputeUpheld && status != DisputeReverted {
+		return ErrInvalidDispute
+	}
+	if d.Status != DisputeOpen {
+		return ErrDisputeResolved
+	}
+	d.Status = status
+	d.ResolverName = resolverName
+	d.ResolvedAt = now
+	return nil
+}
+
+// RevertMark removes a team's mark after a dispute and checks the board again,
+// reopening a game the mark had decided
+func (g *Game) RevertMark(row, col int, team TeamID) error {
+	if !g.Started() {
+		return ErrGameNotStarted
+	}
+	if !g.Board.InBounds(row, col) {
+		return ErrInvalidPosition
+	}
+	if !g.Board.Cells[row][col].HasMark(team) {
+		return ErrNoMarkToDispute
+	}
+
+	if err := g.ClearCellMark(row, col, team); err != nil {
+		return err
+	}
+	g.CheckWin()
+	return nil
+}
@@ -0,0 +1,85 @@
+package game
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDisputeRaise(t *testing.T) {
+	g := NewGameWithSize(RuleNormal, 3)
+	var disputes Disputes
+	if _, err := disputes.Raise(g, Dispute{Row: 0, Col: 0, By: TeamBlue, Reason: "x"}); err != ErrGameNotStarted {
+		t.Errorf("Dispute before the start should fail, got: %v", err)
+	}
+
+	g.Start()
+	g.MarkCell(0, 0, TeamRed)
+	if _, err := disputes.Raise(g, Dispute{Row: 0, Col: 1, By: TeamBlue, Reason: "x"}); err != ErrNoMarkToDispute {
+		t.Errorf("Dispute of an empty cell should fail, got: %v", err)
+	}
+	if _, err := disputes.Raise(g, Dispute{Row: 0, Col: 0, By: TeamRed, Reason: "x"}); err == nil {
+		t.Error("Dispute of the own team's mark should fail")
+	}
+	if _, err := disputes.Raise(g, Dispute{Row: 0, Col: 0, By: TeamBlue, Reason: "  "}); err == nil {
+		t.Error("Dispute without a reason should fail")
+	}
+	if _, err := disputes.Raise(g, Dispute{Row: 0, Col: 0, By: TeamBlue, Reason: strings.Repeat("a", MaxDisputeReason+1)}); err != ErrInvalidDispute {
+		t.Errorf("Overlong reason should fail, got: %v", err)
+	}
+
+	d, err := disputes.Raise(g, Dispute{Row: 0, Col: 0, By: TeamBlue, Reason: " not on stream "})
+	if err != nil {
+		t.Fatalf("Raise failed: %v", err)
+	}
+	if d.ID != 1 || d.Team != TeamRed || d.Status != DisputeOpen || d.Reason != "not on stream" {
+		t.Errorf("Unexpected dispute: %+v", d)
+	}
+	if _, err := disputes.Raise(g, Dispute{Row: 0, Col: 0, By: TeamBlue, Reason: "again"}); err != ErrDisputeOpen {
+		t.Errorf("Second open dispute of a mark should fail, got: %v", err)
+	}
+	if len(disputes.Open()) != 1 {
+		t.Errorf("Expected 1 open dispute, got %d", len(disputes.Open()))
+	}
+}
+
+func TestDisputeResolve(t *testing.T) {
+	g := NewGameWithSize(RuleNormal, 3)
+	g.Start()
+	g.MarkCell(0, 0, TeamRed)
+	g.MarkCell(0, 1, TeamRed)
+	g.MarkCell(0, 2, TeamRed)
+	if g.Status != StatusFinished {
+		t.Fatal("Red should have won")
+	}
+
+	var disputes Disputes
+	disputes.Raise(g, Dispute{Row: 0, Col: 1, By: TeamBlue, Reason: "wrong item"})
+	disputes.Raise(g, Dispute{Row: 0, Col: 2, By: TeamBlue, Reason: "too late"})
+
+	d, _ := disputes.Find(1)
+	if err := g.RevertMark(d.Row, d.Col, d.Team); err != nil {
+		t.Fatalf("RevertMark failed: %v", err)
+	}
+	now := time.Now()
+	d.Resolve(DisputeReverted, "referee", now)
+	if g.Status != StatusPlaying || g.Winner != nil {
+		t.Errorf("Reverting a winning mark should reopen the game, got status %v", g.Status)
+	}
+
+	d, _ = disputes.Find(2)
+	d.Resolve(DisputeUpheld, "referee", now)
+	if g.Board.Cells[0][2].MarkedBy() != TeamRed {
+		t.Error("Upheld mark should stay")
+	}
+
+	if _, err := disputes.Find(1); err != ErrDisputeResolved {
+		t.Errorf("Resolved dispute should not be found as open, got: %v", err)
+	}
+	if _, err := disputes.Find(3); err != ErrDisputeNotFound {
+		t.Errorf("Unknown dispute should fail, got: %v", err)
+	}
+	if len(disputes.Open()) != 0 || disputes[0].Status != DisputeReverted || disputes[1].ResolverName != "referee" {
+		t.Errorf("Resolutions should be recorded, got %+v", disputes)
+	}
+}
@@ -0,0 +1,109 @@
+package game
+
+import (
+	"slices"
+	"time"
+)
+
+// EventAction identifies a logged game action
+type EventAction string
+
+const (
+	ActionStart     EventAction = "start"
+	ActionMark      EventAction = "mark"
+	ActionForceMark EventAction = "force_mark" // Referee overwrite in normal rule
+	ActionUnmark    EventAction = "unmark"
+	ActionClearMark EventAction = "clear_mark"
+	ActionSettle    EventAction = "settle"
+	ActionReset     EventAction = "reset"
+	ActionUndo      EventAction = "undo"
+	ActionRedo      EventAction = "redo"
+	ActionTimeout   EventAction = "timeout" // Logged by the server when the time limit runs out
+
+	// Claim verification
+	ActionClaim        EventAction = "claim"
+	ActionConfirmClaim EventAction = "confirm_claim"
+	ActionRejectClaim  EventAction = "reject_claim"
+
+	// Disputes, only reverting a mark changes the game
+	ActionDispute    EventAction = "dispute"
+	ActionUpholdMark EventAction = "uphold_mark"
+	ActionRevertMark EventAction = "revert_mark"
+
+	// Progress counters, one step at a time
+	ActionProgressUp   EventAction = "progress_up"
+	ActionProgressDown EventAction = "progress_down"
+
+	// Referee cell locks, one action per lock state
+	ActionLockCell EventAction = "lock_cell"
+	ActionVoidCell EventAction = "void_cell"
+	ActionOpenCell EventAction = "open_cell"
+)
+
+// HasCell reports whether the action targets a single cell
+func (a EventAction) HasCell() bool {
+	switch a {
+	case ActionMark, ActionForceMark, ActionUnmark, ActionClearMark, ActionClaim, ActionConfirmClaim, ActionRejectClaim,
+		ActionDispute, ActionUpholdMark, ActionRevertMark, ActionProgressUp, ActionProgressDown,
+		ActionLockCell, ActionVoidCell, ActionOpenCell:
+		return true
+	}
+	return false
+}
+
+// Event is a single entry of a room's append-only action log
+// Row and Col are only meaningful for cell actions
+type Event struct {
+	Seq       int         `json:"seq"`
+	Time      time.Time   `json:"time"`
+	ActorID   string      `json:"actor_id"`
+	ActorName string      `json:"actor_name"`
+	Action    EventAction `json:"action"`
+	Row       int         `json:"row"`
+	Col       int         `json:"col"`
+	Team      TeamID      `json:"team,omitempty"`
+	Game      *Game       `json:"game,omitempty"` // Game right after a start, base for replays
+}
+
+// EventLog is an append-only list of events with increasing sequence numbers
+type EventLog []Event
+
+// Append adds an event, assigning it the next sequence number
+func (l *EventLog) Append(e Event) Event {
+	e.Seq = l.LastSeq() + 1
+	*l = append(*l, e)
+	return e
+}
+
+// LastSeq returns the sequence number of the latest event, 0 if empty
+func (l EventLog) LastSeq() int {
+	if len(l) == 0 {
+		return 0
+	}
+	return l[len(l)-1].Seq
+}
+
+// Prune keeps the events of the last games games, dropping those from before the earliest of their starts
+// Sequence numbers stay as they are, so asking for events since a seq still works
+func (l *EventLog) Prune(games int) {
+	starts := 0
+	for i := len(*l) - 1; i > 0; i-- {
+		if (*l)[i].Action != ActionStart {
+			continue
+		}
+		if starts++; starts == games {
+			*l = slices.Clone((*l)[i:])
+			return
+		}
+	}
+}
+
+// Since returns a copy of the events with sequence numbers greater than seq
+func (l EventLog) Since(seq int) []Event {
+	for i, e := range l {
+		if e.Seq > seq {
+			return append([]Event(nil), l[i:]...)
+		}
+	}
+	return []Event{}
+}
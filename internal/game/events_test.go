@@ -0,0 +1,55 @@
+package game
+
+import "testing"
+
+func TestEventLogAppendAndSince(t *testing.T) {
+	var log EventLog
+	if log.LastSeq() != 0 {
+		t.Fatalf("Empty log should have LastSeq 0, got %d", log.LastSeq())
+	}
+
+	log.Append(Event{Action: ActionStart})
+	e := log.Append(Event{Action: ActionMark, Row: 1, Col: 2, Team: TeamRed})
+	log.Append(Event{Action: ActionSettle, Team: TeamRed})
+
+	if e.Seq != 2 || log.LastSeq() != 3 {
+		t.Errorf("Sequence numbers should increase from 1, got %d and last %d", e.Seq, log.LastSeq())
+	}
+
+	since := log.Since(1)
+	if len(since) != 2 || since[0].Action != ActionMark || since[1].Seq != 3 {
+		t.Errorf("Since(1) should return the last two events, got: %+v", since)
+	}
+	since[0].Action = ActionReset
+	if log[1].Action != ActionMark {
+		t.Error("Since should return a copy")
+	}
+	if len(log.Since(3)) != 0 {
+		t.Error("Since(LastSeq) should return no events")
+	}
+
+	if !ActionClearMark.HasCell() || ActionSettle.HasCell() {
+		t.Error("HasCell should only be true for cell actions")
+	}
+}
+
+func TestEventLogPrune(t *testing.T) {
+	var log EventLog
+	for range 3 {
+		log.Append(Event{Action: ActionStart})
+		log.Append(Event{Action: ActionMark, Team: TeamRed})
+		log.Append(Event{Action: ActionReset})
+	}
+
+	log.Prune(5)
+	if len(log) != 9 {
+		t.Errorf("Log with fewer games should be kept whole, got %d events", len(log))
+	}
+	log.Prune(2)
+	if len(log) != 6 || log[0].Seq != 4 || log[0].Action != ActionStart || log.LastSeq() != 9 {
+		t.Errorf("Only the last 2 games should be kept, got: %+v", log)
+	}
+	if e := log.Append(Event{Action: ActionStart}); e.Seq != 10 {
+		t.Errorf("Sequence numbers should go on after pruning, got %d", e.Seq)
+	}
+}
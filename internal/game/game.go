@@ -2,6 +2,10 @@ package game
 
 import (
 	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
 )
 
 var (
@@ -11,182 +15,319 @@ var (
 	ErrRowLocked         = errors.New("row is locked")
 	ErrRowLimitExceeded  = errors.New("row mark limit exceeded")
 	ErrAlreadySettled    = errors.New("player already settled")
-	ErrCannotSettleYet   = errors.New("need at least 2 cells in row 5 to settle")
+	ErrCannotSettleYet   = errors.New("not enough cells in the last phase to settle")
+	ErrNoSettlement      = errors.New("this rule has no settlement")
+	ErrInvalidPosition   = errors.New("invalid cell position")
+	ErrInvalidBoardSize  = errors.New("invalid board size")
+	ErrUnknownTeam       = errors.New("unknown team")
+	ErrInvalidTeams      = errors.New("invalid team list")
+	ErrInvalidLinesToWin = errors.New("invalid number of lines to win")
 )
 
-// NewGame creates a new game with specified rule
+// NewGame creates a new game with specified rule on a default-sized board
 func NewGame(rule GameRule) *Game {
+	return NewGameWithSize(rule, DefaultBoardSize)
+}
+
+// NewGameWithSize creates a new game with specified rule and board size
+func NewGameWithSize(rule GameRule, size int) *Game {
 	g := &Game{
-		Board:           NewBoard(),
-		Rule:            rule,
-		PhaseConfig:     DefaultPhaseConfig(),
-		Status:          StatusWaiting,
-		BingoLine:       -1,
-		RedUnlockedRow:  0,
-		BlueUnlockedRow: 0,
-	}
+		Board:       NewBoard(size),
+		Rule:        rule,
+		PhaseConfig: DefaultPhaseConfig(size),
+		Status:      StatusWaiting,
+		Teams:       DefaultTeams(),
+		BingoLine:   -1,
+	}
+	g.resetTeamStates()
 	return g
 }
 
-// Start begins the game
-func (g *Game) Start() error {
-	if g.Status == StatusPlaying {
-		return errors.New("game already in progress")
+// ValidBoardSize reports whether size is an allowed board size
+func ValidBoardSize(size int) bool {
+	return size >= MinBoardSize && size <= MaxBoardSize
+}
+
+// SetBoardSize resizes the board, only allowed before the game starts
+// Cell texts are cleared and the phase config is reset to the defaults for the new size
+func (g *Game) SetBoardSize(size int) error {
+	if g.Status != StatusWaiting {
+		return errors.New("can only change board size in waiting state")
 	}
-	g.Status = StatusPlaying
+	if !ValidBoardSize(size) {
+		return ErrInvalidBoardSize
+	}
+	if size == g.Board.Size() {
+		return nil
+	}
+
+	g.Board = NewBoard(size)
+	g.PhaseConfig = DefaultPhaseConfig(size)
+	g.LinesToWin = min(g.LinesToWin, LineCount(size))
+	g.fitPatterns()
+	g.fitStartCells()
+	g.Reset()
 	return nil
 }
 
-// MarkCell marks a cell for a player
-func (g *Game) MarkCell(row, col int, player PlayerColor) error {
-	if g.Status == StatusWaiting {
-		return ErrGameNotStarted
+// LineCount returns the number of lines on a board: rows, columns and both diagonals
+func LineCount(size int) int {
+	return 2*size + 2
+}
+
+// SetLinesToWin sets how many distinct lines a team needs to win in normal rule (not while playing)
+func (g *Game) SetLinesToWin(lines int) error {
+	if g.Status == StatusPlaying || g.Status == StatusCountdown {
+		return errors.New("cannot change lines to win while playing")
 	}
-	if g.Status == StatusFinished {
-		return ErrGameFinished
+	if lines < 1 || lines > LineCount(g.Board.Size()) {
+		return fmt.Errorf("%w: need 1 to %d", ErrInvalidLinesToWin, LineCount(g.Board.Size()))
 	}
+	g.LinesToWin = lines
+	return nil
+}
 
-	if row < 0 || row > 4 || col < 0 || col > 4 {
-		return errors.New("invalid cell position")
+// SetRule changes the rule and phase config, resetting the board
+// Teams, board size and time limit are kept
+func (g *Game) SetRule(rule GameRule, config PhaseConfig) error {
+	if g.Status == StatusPlaying || g.Status == StatusCountdown {
+		return errors.New("cannot change rule while playing")
+	}
+	if err := config.Validate(g.Board.Size()); err != nil {
+		return err
 	}
 
-	cell := &g.Board.Cells[row][col]
+	g.Rule = rule
+	g.PhaseConfig = config
+	g.Reset()
+	return nil
+}
 
-	switch g.Rule {
-	case RuleNormal:
-		if err := g.markNormal(cell, player); err != nil {
-			return err
+// SetTeams replaces the team list, only allowed before the game starts
+func (g *Game) SetTeams(teams []Team) error {
+	if g.Status != StatusWaiting {
+		return errors.New("can only change teams in waiting state")
+	}
+	if len(teams) < MinTeams || len(teams) > MaxTeams {
+		return fmt.Errorf("%w: need %d to %d teams", ErrInvalidTeams, MinTeams, MaxTeams)
+	}
+
+	seen := make(map[TeamID]bool, len(teams))
+	result := make([]Team, len(teams))
+	for i, t := range teams {
+		if t.ID == TeamNone || t.ID == "none" {
+			return fmt.Errorf("%w: team %d has no id", ErrInvalidTeams, i+1)
 		}
-	case RuleBlackout:
-		if err := g.markBlackout(cell, player); err != nil {
-			return err
+		if seen[t.ID] {
+			return fmt.Errorf("%w: duplicate team id %q", ErrInvalidTeams, t.ID)
+		}
+		seen[t.ID] = true
+		if t.Name == "" {
+			t.Name = string(t.ID)
+		}
+		result[i] = t
+	}
+
+	g.Teams = result
+	g.fitStartCells()
+	g.Reset()
+	return nil
+}
+
+// HasTeam reports whether the team takes part in the game
+func (g *Game) HasTeam(team TeamID) bool {
+	return g.Team(team) != nil
+}
+
+// Team returns the team with the given ID, or nil
+func (g *Game) Team(team TeamID) *Team {
+	for i := range g.Teams {
+		if g.Teams[i].ID == team {
+			return &g.Teams[i]
 		}
-	case RulePhase:
-		if err := g.markPhase(row, col, player); err != nil {
+	}
+	return nil
+}
+
+// resetTeamStates creates empty per-team tracking for every team
+func (g *Game) resetTeamStates() {
+	phases := g.phaseCount()
+	g.TeamStates = make(map[TeamID]*TeamState, len(g.Teams))
+	for _, t := range g.Teams {
+		g.TeamStates[t.ID] = newTeamState(phases)
+	}
+}
+
+// Start begins the game
+func (g *Game) Start() error {
+	return g.StartAt(time.Now())
+}
+
+// StartAt starts the game at now
+// With a countdown the game is revealed and its clock starts once the countdown is over
+func (g *Game) StartAt(now time.Time) error {
+	if g.Status == StatusPlaying || g.Status == StatusCountdown {
+		return errors.New("game already in progress")
+	}
+	if g.Status == StatusWaiting {
+		if err := g.checkStartCells(); err != nil {
 			return err
 		}
+		g.applyStartCells()
+	}
+	g.Status = StatusPlaying
+	if g.Countdown > 0 {
+		g.Status = StatusCountdown
 	}
+	g.StartTime = now.Add(g.Countdown)
+	g.EndTime = time.Time{}
+	return nil
+}
 
-	// Check for winner (phase rule checks after mark)
-	if g.Rule != RulePhase {
-		g.CheckWin()
+// Started reports whether the game has been revealed, marks are only accepted from then on
+func (g *Game) Started() bool {
+	return g.Status == StatusPlaying || g.Status == StatusFinished
+}
+
+// MarkCell marks a cell for a team
+func (g *Game) MarkCell(row, col int, team TeamID) error {
+	return g.MarkCellBy(row, col, Mark{Team: team})
+}
+
+// MarkCellBy marks a cell for mark.Team, recording the player who made the mark
+func (g *Game) MarkCellBy(row, col int, mark Mark) error {
+	if err := g.checkMark(row, col, mark.Team); err != nil {
+		return err
 	}
 
+	if err := g.rule().Mark(g, row, col, mark); err != nil {
+		return err
+	}
+	g.Board.Cells[row][col].Intents = nil
+
+	g.CheckWin()
 	return nil
 }
 
+// checkMark checks that the game takes marks and the position and team are valid
+func (g *Game) checkMark(row, col int, team TeamID) error {
+	if !g.Started() {
+		return ErrGameNotStarted
+	}
+	if g.Status == StatusFinished {
+		return ErrGameFinished
+	}
+
+	if !g.Board.InBounds(row, col) {
+		return ErrInvalidPosition
+	}
+	if !g.HasTeam(team) {
+		return ErrUnknownTeam
+	}
+	return g.Board.Cells[row][col].markable()
+}
+
 // MarkCellForce marks a cell with force overwrite (for referee)
-func (g *Game) MarkCellForce(row, col int, player PlayerColor) error {
-	if g.Status == StatusWaiting {
+// Forcing TeamNone clears the cell
+func (g *Game) MarkCellForce(row, col int, team TeamID) error {
+	return g.MarkCellForceBy(row, col, Mark{Team: team})
+}
+
+// MarkCellForceBy force marks a cell for mark.Team, recording who made the mark
+func (g *Game) MarkCellForceBy(row, col int, mark Mark) error {
+	if !g.Started() {
 		return ErrGameNotStarted
 	}
 
-	if row < 0 || row > 4 || col < 0 || col > 4 {
-		return errors.New("invalid cell position")
+	if !g.Board.InBounds(row, col) {
+		return ErrInvalidPosition
+	}
+	if mark.Team != TeamNone && !g.HasTeam(mark.Team) {
+		return ErrUnknownTeam
 	}
 
 	cell := &g.Board.Cells[row][col]
+	if err := cell.markable(); err != nil && mark.Team != TeamNone {
+		return err
+	}
 
-	cell.MarkedBy = player
-	cell.SecondMark = ColorNone
-	cell.Times = 0
-
-	if g.Rule != RulePhase {
-		g.CheckWin()
+	cell.Marks = nil
+	if mark.Team != TeamNone {
+		cell.Marks = []Mark{mark}
+		cell.Intents = nil
 	}
+	cell.Times = 0
 
+	g.CheckWin()
 	return nil
 }
 
 // markNormal handles marking for normal rule
-func (g *Game) markNormal(cell *Cell, player PlayerColor) error {
-	if cell.MarkedBy != ColorNone {
+func (g *Game) markNormal(cell *Cell, mark Mark) error {
+	if len(cell.Marks) > 0 {
 		return ErrCellAlreadyMarked
 	}
 
-	cell.MarkedBy = player
+	cell.Marks = []Mark{mark}
 	return nil
 }
 
 // markBlackout handles marking for blackout rule
-// Both players can mark the same cell, first marker in MarkedBy, second in SecondMark
-func (g *Game) markBlackout(cell *Cell, player PlayerColor) error {
-	// Check if player already marked this cell
-	if cell.MarkedBy == player {
-		return errors.New("player already marked this cell")
-	}
-	if cell.SecondMark == player {
+// Every team can mark the same cell once, marks are kept in order
+func (g *Game) markBlackout(cell *Cell, mark Mark) error {
+	// Check if team already marked this cell
+	if cell.HasMark(mark.Team) {
 		return errors.New("player already marked this cell")
 	}
 
-	// First marker
-	if cell.MarkedBy == ColorNone {
-		cell.MarkedBy = player
-		cell.Times = 1
-		return nil
-	}
-
-	// Second marker (different color from first)
-	if cell.SecondMark == ColorNone {
-		cell.SecondMark = player
-		cell.Times = 2
-		return nil
-	}
-
-	// Cell already has both colors marked
-	return ErrCellAlreadyMarked
+	cell.Marks = append(cell.Marks, mark)
+	cell.Times = len(cell.Marks)
+	return nil
 }
 
 // markPhase handles marking for phase rule
-func (g *Game) markPhase(row, col int, player PlayerColor) error {
+func (g *Game) markPhase(row, col int, mark Mark) error {
 	cell := &g.Board.Cells[row][col]
+	state := g.TeamStates[mark.Team]
 
-	var unlockedRow *int
-	var rowMarks *int
-	if player == ColorRed {
-		unlockedRow = &g.RedUnlockedRow
-		rowMarks = &g.RedRowMarks[row]
-	} else {
-		unlockedRow = &g.BlueUnlockedRow
-		rowMarks = &g.BlueRowMarks[row]
+	phase := g.phaseOf(row, col)
+	if phase < 0 {
+		return ErrCellNotInPhase
 	}
+	config := g.phases()[phase]
 
-	// Check if row is locked
-	if row > *unlockedRow {
+	// Check if the phase is locked
+	if phase > state.UnlockedRow {
 		return ErrRowLocked
 	}
 
-	// Check per-row limit
-	if *rowMarks >= g.PhaseConfig.CellsPerRow {
+	// Check per-phase limit
+	if state.RowMarks[phase] >= config.Limit {
 		return ErrRowLimitExceeded
 	}
 
-	// Check if player already marked this cell
-	if cell.MarkedBy == player || cell.SecondMark == player {
+	// Check if team already marked this cell
+	if cell.HasMark(mark.Team) {
 		return errors.New("player already marked this cell")
 	}
 
-	// Mark the cell
-	if cell.MarkedBy == ColorNone {
-		cell.MarkedBy = player
-	} else if cell.SecondMark == ColorNone {
-		cell.SecondMark = player
-		cell.Times = 1
-	}
+	// Mark the cell; Times counts the marks after the first one
+	cell.Marks = append(cell.Marks, mark)
+	cell.Times = len(cell.Marks) - 1
 
-	// Update row marks count
-	*rowMarks++
+	// Update phase marks count
+	state.RowMarks[phase]++
 
-	// Check for row unlock: only when marking the current highest unlocked row
-	// and reaching the threshold, unlock the next row
-	if row == *unlockedRow && *unlockedRow < 4 {
-		if *rowMarks >= g.PhaseConfig.UnlockThreshold {
-			*unlockedRow++
+	// Check for phase unlock: only when marking the current highest unlocked phase
+	// and reaching the threshold, unlock the next phase
+	if phase == state.UnlockedRow && state.UnlockedRow < g.lastPhase() {
+		if state.RowMarks[phase] >= config.UnlockThreshold {
+			state.UnlockedRow++
 		}
 	}
 
 	// Check for Bingo
-	if g.BingoAchiever == ColorNone {
+	if g.BingoAchiever == TeamNone {
 		g.checkPhaseBingo()
 	}
 
@@ -195,16 +336,17 @@ func (g *Game) markPhase(row, col int, player PlayerColor) error {
 
 // checkPhaseBingo checks for vertical and diagonal Bingo
 func (g *Game) checkPhaseBingo() bool {
-	for col := 0; col < 5; col++ {
+	size := g.Board.Size()
+	for col := 0; col < size; col++ {
 		if g.checkPhaseLineBingo(0, col, 1, 0, col) {
 			return true
 		}
 	}
 
-	if g.checkPhaseLineBingo(0, 0, 1, 1, 5) {
+	if g.checkPhaseLineBingo(0, 0, 1, 1, size) {
 		return true
 	}
-	if g.checkPhaseLineBingo(0, 4, 1, -1, 6) {
+	if g.checkPhaseLineBingo(0, size-1, 1, -1, size+1) {
 		return true
 	}
 
@@ -212,146 +354,184 @@ func (g *Game) checkPhaseBingo() bool {
 }
 
 // checkPhaseLineBingo checks if a line has Bingo
+// Teams are checked in display order
 func (g *Game) checkPhaseLineBingo(startRow, startCol, dRow, dCol, lineIndex int) bool {
-	redCount := 0
-	blueCount := 0
-
-	for i := 0; i < 5; i++ {
-		cell := g.Board.Cells[startRow+i*dRow][startCol+i*dCol]
-		if cell.MarkedBy == ColorRed || cell.SecondMark == ColorRed {
-			redCount++
-		}
-		if cell.MarkedBy == ColorBlue || cell.SecondMark == ColorBlue {
-			blueCount++
+	size := g.Board.Size()
+
+	for _, t := range g.Teams {
+		count, marked := 0, 0
+		for i := 0; i < size; i++ {
+			cell := &g.Board.Cells[startRow+i*dRow][startCol+i*dCol]
+			switch {
+			case cell.Void():
+				count++
+			case cell.HasMark(t.ID):
+				count++
+				marked++
+			}
 		}
-	}
 
-	if redCount == 5 && g.BingoAchiever == ColorNone {
-		g.BingoAchiever = ColorRed
-		g.BingoLine = lineIndex
-		return true
-	}
-	if blueCount == 5 && g.BingoAchiever == ColorNone {
-		g.BingoAchiever = ColorBlue
-		g.BingoLine = lineIndex
-		return true
+		// Void cells are free spaces, but a line needs at least one real mark
+		if count == size && marked > 0 && g.BingoAchiever == TeamNone {
+			g.BingoAchiever = t.ID
+			g.BingoLine = lineIndex
+			return true
+		}
 	}
 
 	return false
 }
 
-// CanSettle checks if a player can trigger settlement
-func (g *Game) CanSettle(player PlayerColor) bool {
-	var rowMarks int
-	if player == ColorRed {
-		rowMarks = g.RedRowMarks[4]
-	} else {
-		rowMarks = g.BlueRowMarks[4]
+// CanSettle checks if a team can trigger settlement
+func (g *Game) CanSettle(team TeamID) bool {
+	state, ok := g.TeamStates[team]
+	if !ok {
+		return false
 	}
-	return rowMarks >= 2
+	return state.RowMarks[g.lastPhase()] >= g.settleMarks()
 }
 
-// Settle triggers settlement for a player
-func (g *Game) Settle(player PlayerColor) error {
+// Settle triggers settlement for a team, in rules that end by settlement
+func (g *Game) Settle(team TeamID) error {
 	if g.Status != StatusPlaying {
 		return ErrGameNotStarted
 	}
+	return g.rule().Settle(g, team)
+}
 
-	if player == ColorRed && g.RedSettled {
-		return ErrAlreadySettled
+// settlePhase settles a team in phase rule
+// The game ends once every team has settled
+func (g *Game) settlePhase(team TeamID) error {
+	state, ok := g.TeamStates[team]
+	if !ok {
+		return ErrUnknownTeam
 	}
-	if player == ColorBlue && g.BlueSettled {
+	if state.Settled {
 		return ErrAlreadySettled
 	}
 
-	// First settler must meet conditions, second settler can settle without conditions
-	if g.FirstSettler == ColorNone {
+	// First settler must meet conditions, later settlers can settle without conditions
+	if g.FirstSettler == TeamNone {
 		// This is the first settler - must meet conditions
-		if !g.CanSettle(player) {
-			return ErrCannotSettleYet
+		if !g.CanSettle(team) {
+			return &settleError{need: g.settleMarks(), phase: g.phaseName(g.lastPhase())}
 		}
-		g.FirstSettler = player
+		g.FirstSettler = team
 	}
-	// Second settler doesn't need to meet any conditions
+	// Later settlers don't need to meet any conditions
 
-	if player == ColorRed {
-		g.RedSettled = true
-	} else {
-		g.BlueSettled = true
-	}
+	state.Settled = true
 
-	if g.RedSettled && g.BlueSettled {
+	if g.allSettled() {
 		g.checkPhaseWin()
 	}
 
 	return nil
 }
 
+// allSettled reports whether every team has settled
+func (g *Game) allSettled() bool {
+	for _, t := range g.Teams {
+		if !g.TeamStates[t.ID].Settled {
+			return false
+		}
+	}
+	return true
+}
+
 // CalculatePhaseScore calculates scores for phase rule
-func (g *Game) CalculatePhaseScore() (redScore, blueScore int) {
-	for row := 0; row < 5; row++ {
-		for col := 0; col < 5; col++ {
-			cell := g.Board.Cells[row][col]
-
-			if cell.MarkedBy == ColorRed {
-				redScore += g.PhaseConfig.RowScores[row]
-			} else if cell.MarkedBy == ColorBlue {
-				blueScore += g.PhaseConfig.RowScores[row]
+// The first marker of a cell gets the phase score, later markers get the reduced score,
+// void cells score nothing
+func (g *Game) CalculatePhaseScore() map[TeamID]int {
+	scores := g.newScores()
+
+	for _, phase := range g.phases() {
+		for _, pos := range phase.Cells {
+			cell := g.Board.Cells[pos[0]][pos[1]]
+			if cell.Void() {
+				continue
 			}
 
-			if cell.SecondMark == ColorRed {
-				redScore += g.PhaseConfig.SecondHalfScores[row]
-			} else if cell.SecondMark == ColorBlue {
-				blueScore += g.PhaseConfig.SecondHalfScores[row]
+			for i, m := range cell.Marks {
+				if i == 0 {
+					scores[m.Team] += phase.Score
+				} else {
+					scores[m.Team] += phase.LaterScore
+				}
 			}
 		}
 	}
 
-	if g.BingoAchiever == ColorRed {
-		redScore += g.PhaseConfig.BingoBonus
-	} else if g.BingoAchiever == ColorBlue {
-		blueScore += g.PhaseConfig.BingoBonus
+	if g.BingoAchiever != TeamNone {
+		scores[g.BingoAchiever] += g.PhaseConfig.BingoBonus
 	}
 
-	if g.FirstSettler == ColorRed {
-		redScore += g.PhaseConfig.FinalBonus
-	} else if g.FirstSettler == ColorBlue {
-		blueScore += g.PhaseConfig.FinalBonus
+	if g.FirstSettler != TeamNone {
+		scores[g.FirstSettler] += g.PhaseConfig.FinalBonus
 	}
 
-	return redScore, blueScore
+	return g.addScoreOffsets(scores)
 }
 
-// CountMarks counts total marks for each player
-func (g *Game) CountMarks() (redCount, blueCount int) {
-	for i := 0; i < 5; i++ {
-		for j := 0; j < 5; j++ {
-			cell := g.Board.Cells[i][j]
-			if cell.MarkedBy == ColorRed || cell.SecondMark == ColorRed {
-				redCount++
+// CountMarks counts total marks for each team, marks on void cells do not count
+func (g *Game) CountMarks() map[TeamID]int {
+	counts := g.newScores()
+
+	size := g.Board.Size()
+	for i := 0; i < size; i++ {
+		for j := 0; j < size; j++ {
+			if g.Board.Cells[i][j].Void() {
+				continue
 			}
-			if cell.MarkedBy == ColorBlue || cell.SecondMark == ColorBlue {
-				blueCount++
+			for _, m := range g.Board.Cells[i][j].Marks {
+				counts[m.Team]++
 			}
 		}
 	}
-	return
+	return counts
+}
+
+// newScores returns a zeroed score map with an entry for every team
+func (g *Game) newScores() map[TeamID]int {
+	scores := make(map[TeamID]int, len(g.Teams))
+	for _, t := range g.Teams {
+		scores[t.ID] = 0
+	}
+	return scores
+}
+
+// leader returns the team with the highest score, or TeamNone on a tie for first
+func (g *Game) leader(scores map[TeamID]int) TeamID {
+	best := TeamNone
+	tied := false
+	for _, t := range g.Teams {
+		switch {
+		case best == TeamNone || scores[t.ID] > scores[best]:
+			best = t.ID
+			tied = false
+		case scores[t.ID] == scores[best]:
+			tied = true
+		}
+	}
+	if tied {
+		return TeamNone
+	}
+	return best
 }
 
 // Reset resets the game board
 func (g *Game) Reset() {
-	g.Board = NewBoard()
+	size := g.Board.Size()
+	g.Board = NewBoard(size)
 	g.Status = StatusWaiting
 	g.Winner = nil
-	g.RedRowMarks = [5]int{}
-	g.BlueRowMarks = [5]int{}
-	g.RedUnlockedRow = 0
-	g.BlueUnlockedRow = 0
-	g.BingoAchiever = ColorNone
+	g.resetTeamStates()
+	g.BingoAchiever = TeamNone
 	g.BingoLine = -1
-	g.RedSettled = false
-	g.BlueSettled = false
-	g.FirstSettler = ColorNone
+	g.FirstSettler = TeamNone
+	g.StartTime = time.Time{}
+	g.EndTime = time.Time{}
+	g.Seed = nil
 }
 
 // GetState returns the current game state
@@ -359,20 +539,69 @@ func (g *Game) GetState() *Game {
 	return g
 }
 
-// CheckWin checks if there is a winner and updates game state
-func (g *Game) CheckWin() *Winner {
-	var winner *Winner
-
-	switch g.Rule {
-	case RuleNormal:
-		winner = g.checkNormalWin()
-	case RuleBlackout:
-		winner = g.checkBlackoutWin()
-	case RulePhase:
-		return nil
+// Clone returns a deep copy of the game, sharing no slices or maps with it
+func (g *Game) Clone() *Game {
+	c := *g
+
+	if g.Board != nil {
+		c.Board = &Board{Cells: make([][]Cell, len(g.Board.Cells))}
+		for i, row := range g.Board.Cells {
+			c.Board.Cells[i] = make([]Cell, len(row))
+			for j, cell := range row {
+				cell.Marks = slices.Clone(cell.Marks)
+				cell.Claims = slices.Clone(cell.Claims)
+				cell.Intents = slices.Clone(cell.Intents)
+				cell.Progress = maps.Clone(cell.Progress)
+				c.Board.Cells[i][j] = cell
+			}
+		}
 	}
 
+	c.PhaseConfig.RowScores = slices.Clone(g.PhaseConfig.RowScores)
+	c.PhaseConfig.SecondHalfScores = slices.Clone(g.PhaseConfig.SecondHalfScores)
+	if g.PhaseConfig.Phases != nil {
+		c.PhaseConfig.Phases = make([]Phase, len(g.PhaseConfig.Phases))
+		for i, p := range g.PhaseConfig.Phases {
+			p.Cells = slices.Clone(p.Cells)
+			c.PhaseConfig.Phases[i] = p
+		}
+	}
+
+	if g.Winner != nil {
+		w := *g.Winner
+		w.Scores = maps.Clone(g.Winner.Scores)
+		w.Cells = slices.Clone(g.Winner.Cells)
+		w.Handicaps = cloneHandicaps(g.Winner.Handicaps)
+		c.Winner = &w
+	}
+
+	if g.Seed != nil {
+		seed := *g.Seed
+		c.Seed = &seed
+	}
+
+	c.Teams = slices.Clone(g.Teams)
+	c.WinPatterns = slices.Clone(g.WinPatterns)
+	c.CustomPattern = cloneMask(g.CustomPattern)
+	c.Handicaps = cloneHandicaps(g.Handicaps)
+
+	if g.TeamStates != nil {
+		c.TeamStates = make(map[TeamID]*TeamState, len(g.TeamStates))
+		for team, state := range g.TeamStates {
+			s := *state
+			s.RowMarks = slices.Clone(state.RowMarks)
+			c.TeamStates[team] = &s
+		}
+	}
+
+	return &c
+}
+
+// CheckWin checks if there is a winner and updates game state
+func (g *Game) CheckWin() *Winner {
+	winner := g.rule().CheckWin(g)
 	if winner != nil {
+		winner.Handicaps = cloneHandicaps(g.Handicaps)
 		g.Status = StatusFinished
 		g.Winner = winner
 	} else {
@@ -385,306 +614,284 @@ func (g *Game) CheckWin() *Winner {
 	return winner
 }
 
-// checkPhaseWin checks and sets winner for phase rule after both settled
+// checkPhaseWin checks and sets winner for phase rule after all teams settled
 func (g *Game) checkPhaseWin() *Winner {
-	redScore, blueScore := g.CalculatePhaseScore()
+	g.Winner = g.decide(WinReasonPhase)
+	g.Status = StatusFinished
 
-	var winner PlayerColor
-	if redScore > blueScore {
-		winner = ColorRed
-	} else if blueScore > redScore {
-		winner = ColorBlue
-	} else {
-		winner = g.FirstSettler
+	return g.Winner
+}
+
+// decide picks the winner by the rule's scores, ties go to the rule's tie break
+func (g *Game) decide(reason WinReason) *Winner {
+	rule := g.rule()
+	scores := rule.Scores(g)
+
+	winner := g.leader(scores)
+	if winner == TeamNone {
+		winner = rule.TieBreak(g)
 	}
 
-	g.Winner = &Winner{
+	return &Winner{
 		Winner:    winner,
-		Reason:    WinReasonPhase,
-		RedScore:  redScore,
-		BlueScore: blueScore,
+		Reason:    reason,
+		Scores:    scores,
+		Handicaps: cloneHandicaps(g.Handicaps),
 	}
-	g.Status = StatusFinished
-
-	return g.Winner
 }
 
 // checkNormalWin checks for winner in normal rule
+// A team wins by completing one of the win patterns (LinesToWin lines by default),
+// teams are checked in display order
 func (g *Game) checkNormalWin() *Winner {
-	// Check rows
-	for row := 0; row < 5; row++ {
-		if winner := g.checkLineWin(row, 0, 0, 1); winner != ColorNone {
-			return g.newBingoWinner(winner)
+	lines := g.CompletedLines()
+	for _, t := range g.Teams {
+		for _, pattern := range g.winPatterns() {
+			if cells, ok := g.matchPattern(pattern, t.ID, lines); ok {
+				winner := g.newBingoWinner(t.ID)
+				winner.Pattern = pattern
+				winner.Cells = cells
+				return winner
+			}
 		}
 	}
 
-	// Check columns
-	for col := 0; col < 5; col++ {
-		if winner := g.checkLineWin(0, col, 1, 0); winner != ColorNone {
-			return g.newBingoWinner(winner)
+	return g.checkFullBoard()
+}
+
+// CompletedLines returns the lines each team has marked entirely, as line indexes:
+// 0..N-1 rows, N..2N-1 columns, 2N diagonal \, 2N+1 diagonal /
+func (g *Game) CompletedLines() map[TeamID][]int {
+	size := g.Board.Size()
+	lines := make(map[TeamID][]int)
+	add := func(index int, team TeamID) {
+		if team != TeamNone {
+			lines[team] = append(lines[team], index)
 		}
 	}
 
-	// Check diagonals
-	if winner := g.checkLineWin(0, 0, 1, 1); winner != ColorNone {
-		return g.newBingoWinner(winner)
+	for row := 0; row < size; row++ {
+		add(row, g.checkLineWin(row, 0, 0, 1))
 	}
-	if winner := g.checkLineWin(0, 4, 1, -1); winner != ColorNone {
-		return g.newBingoWinner(winner)
+	for col := 0; col < size; col++ {
+		add(size+col, g.checkLineWin(0, col, 1, 0))
 	}
+	add(2*size, g.checkLineWin(0, 0, 1, 1))
+	add(2*size+1, g.checkLineWin(0, size-1, 1, -1))
 
-	return g.checkFullBoard()
+	return lines
 }
 
 // newBingoWinner creates a Winner struct for bingo win
-func (g *Game) newBingoWinner(winner PlayerColor) *Winner {
-	redCount, blueCount := g.CountMarks()
+func (g *Game) newBingoWinner(winner TeamID) *Winner {
 	return &Winner{
-		Winner:    winner,
-		Reason:    WinReasonBingo,
-		RedScore:  redCount,
-		BlueScore: blueCount,
+		Winner: winner,
+		Reason: WinReasonBingo,
+		Scores: g.markScores(),
 	}
 }
 
-// checkLineWin checks if a line is completely marked by one player
-func (g *Game) checkLineWin(startRow, startCol, dRow, dCol int) PlayerColor {
-	firstCell := g.Board.Cells[startRow][startCol]
-	if firstCell.MarkedBy == ColorNone {
-		return ColorNone
-	}
-
-	for i := 1; i < 5; i++ {
-		cell := g.Board.Cells[startRow+i*dRow][startCol+i*dCol]
-		if cell.MarkedBy != firstCell.MarkedBy {
-			return ColorNone
+// checkLineWin checks if a line is completely marked by one team
+// Void cells count for every team, a line of only void cells counts for none
+func (g *Game) checkLineWin(startRow, startCol, dRow, dCol int) TeamID {
+	first := TeamNone
+	for i := 0; i < g.Board.Size(); i++ {
+		cell := &g.Board.Cells[startRow+i*dRow][startCol+i*dCol]
+		switch {
+		case cell.Void():
+			// Free space
+		case cell.MarkedBy() == TeamNone:
+			return TeamNone
+		case first == TeamNone:
+			first = cell.MarkedBy()
+		case cell.MarkedBy() != first:
+			return TeamNone
 		}
 	}
 
-	return firstCell.MarkedBy
+	return first
 }
 
 // checkFullBoard checks if the board is full and determines winner
 func (g *Game) checkFullBoard() *Winner {
-	redCount, blueCount := g.CountMarks()
-	total := redCount + blueCount
-
-	if total < 25 {
-		return nil
+	counts := g.CountMarks()
+	total := 0
+	for _, c := range counts {
+		total += c
 	}
 
-	var winner PlayerColor
-	if redCount > blueCount {
-		winner = ColorRed
-	} else if blueCount > redCount {
-		winner = ColorBlue
-	} else {
-		winner = ColorNone
+	if total < g.cellCount() {
+		return nil
 	}
 
+	scores := g.markScores()
 	return &Winner{
-		Winner:    winner,
-		Reason:    WinReasonFullBoard,
-		RedScore:  redCount,
-		BlueScore: blueCount,
+		Winner: g.leader(scores),
+		Reason: WinReasonFullBoard,
+		Scores: scores,
 	}
 }
 
 // checkBlackoutWin checks for winner in blackout rule
 func (g *Game) checkBlackoutWin() *Winner {
-	redCount, blueCount := g.CountMarks()
-
-	if redCount == 25 {
-		return &Winner{
-			Winner:    ColorRed,
-			Reason:    WinReasonBlackout,
-			RedScore:  redCount,
-			BlueScore: blueCount,
-		}
-	}
-	if blueCount == 25 {
-		return &Winner{
-			Winner:    ColorBlue,
-			Reason:    WinReasonBlackout,
-			RedScore:  redCount,
-			BlueScore: blueCount,
+	counts := g.CountMarks()
+
+	for _, t := range g.Teams {
+		if counts[t.ID] == g.cellCount() {
+			return &Winner{
+				Winner: t.ID,
+				Reason: WinReasonBlackout,
+				Scores: g.markScores(),
+			}
 		}
 	}
 
 	return nil
 }
 
+// cellCount returns the number of cells on the board that count, void cells excluded
+func (g *Game) cellCount() int {
+	size := g.Board.Size()
+	count := size * size
+	for _, row := range g.Board.Cells {
+		for _, cell := range row {
+			if cell.Void() {
+				count--
+			}
+		}
+	}
+	return count
+}
+
 // SetCellText sets the text of a cell
 func (g *Game) SetCellText(row, col int, text string) error {
-	if row < 0 || row > 4 || col < 0 || col > 4 {
-		return errors.New("invalid cell position")
+	if !g.Board.InBounds(row, col) {
+		return ErrInvalidPosition
 	}
 
 	g.Board.Cells[row][col].Text = text
+	g.Seed = nil
 	return nil
 }
 
 // SetAllCellTexts sets all cell texts at once
 func (g *Game) SetAllCellTexts(texts []string) error {
-	if len(texts) != 25 {
-		return errors.New("must provide exactly 25 texts")
+	size := g.Board.Size()
+	if len(texts) != size*size {
+		return fmt.Errorf("must provide exactly %d texts", size*size)
+	}
+
+	for row := 0; row < size; row++ {
+		for col := 0; col < size; col++ {
+			g.Board.Cells[row][col].Text = texts[row*size+col]
+		}
 	}
+	g.Seed = nil
+	return nil
+}
 
-	for row := 0; row < 5; row++ {
-		for col := 0; col < 5; col++ {
-			g.Board.Cells[row][col].Text = texts[row*5+col]
+// GenerateBoard fills the board from a goal pool with a seed and records the seed
+func (g *Game) GenerateBoard(goals []Goal, seed int64) error {
+	texts, err := GenerateBoard(goals, g.Board.Size(), seed)
+	if err != nil {
+		return err
+	}
+	if err := g.SetAllCellTexts(texts); err != nil {
+		return err
+	}
+	targets := make(map[string]int, len(goals))
+	for _, goal := range goals {
+		targets[goal.Text] = goal.Target
+	}
+	for row := range g.Board.Cells {
+		for col := range g.Board.Cells[row] {
+			g.SetCellTarget(row, col, targets[texts[row*len(g.Board.Cells)+col]])
 		}
 	}
+	g.Seed = &seed
 	return nil
 }
 
 // UnmarkCell removes all marks from a cell (for referee)
-// For clearing a specific color, use ClearCellMark
+// For clearing a specific team, use ClearCellMark
 func (g *Game) UnmarkCell(row, col int) error {
-	if g.Status == StatusWaiting {
+	if !g.Started() {
 		return ErrGameNotStarted
 	}
 
-	if row < 0 || row > 4 || col < 0 || col > 4 {
-		return errors.New("invalid cell position")
+	if !g.Board.InBounds(row, col) {
+		return ErrInvalidPosition
 	}
 
 	cell := &g.Board.Cells[row][col]
+	marks := cell.Marks
 
-	if g.Rule == RulePhase {
-		// Track which colors need row unlock recheck
-		needRedRecheck := false
-		needBlueRecheck := false
-
-		// Update row marks count
-		if cell.MarkedBy == ColorRed && g.RedRowMarks[row] > 0 {
-			g.RedRowMarks[row]--
-			needRedRecheck = true
-		} else if cell.MarkedBy == ColorBlue && g.BlueRowMarks[row] > 0 {
-			g.BlueRowMarks[row]--
-			needBlueRecheck = true
-		}
-		if cell.SecondMark == ColorRed && g.RedRowMarks[row] > 0 {
-			g.RedRowMarks[row]--
-			needRedRecheck = true
-		} else if cell.SecondMark == ColorBlue && g.BlueRowMarks[row] > 0 {
-			g.BlueRowMarks[row]--
-			needBlueRecheck = true
-		}
-
-		// Recheck row unlock for affected colors
-		if needRedRecheck {
-			g.recheckPhaseRowUnlock(ColorRed)
-		}
-		if needBlueRecheck {
-			g.recheckPhaseRowUnlock(ColorBlue)
-		}
-
-		// Recheck Bingo status
-		g.recheckPhaseBingo()
-	}
-
-	cell.MarkedBy = ColorNone
-	cell.SecondMark = ColorNone
+	cell.Marks = nil
 	cell.Times = 0
 
-	// Re-check winner status (phase rule doesn't check here)
-	if g.Rule != RulePhase {
-		g.CheckWin()
-	}
-
+	g.rule().Unmarked(g, row, col, marks)
+	g.CheckWin()
 	return nil
 }
 
-// ClearCellMark clears a specific color mark from a cell
-// Used for blackout and phase rules where both colors can be on the same cell
-func (g *Game) ClearCellMark(row, col int, player PlayerColor) error {
-	if g.Status == StatusWaiting {
+// ClearCellMark clears a specific team's mark from a cell
+// Used for blackout and phase rules where several teams can be on the same cell
+func (g *Game) ClearCellMark(row, col int, team TeamID) error {
+	if !g.Started() {
 		return ErrGameNotStarted
 	}
 
-	if row < 0 || row > 4 || col < 0 || col > 4 {
-		return errors.New("invalid cell position")
+	if !g.Board.InBounds(row, col) {
+		return ErrInvalidPosition
 	}
 
 	cell := &g.Board.Cells[row][col]
-	cleared := false
-
-	// Handle based on which mark to clear
-	if cell.MarkedBy == player {
-		// First mark is the one to clear
-		// Promote second mark to first if exists
-		cell.MarkedBy = cell.SecondMark
-		cell.SecondMark = ColorNone
-		if cell.Times > 0 {
-			cell.Times--
-		}
-		cleared = true
-	} else if cell.SecondMark == player {
-		// Second mark is the one to clear
-		cell.SecondMark = ColorNone
-		if cell.Times > 0 {
-			cell.Times--
-		}
-		cleared = true
+
+	// Later marks are promoted when an earlier one is cleared
+	cleared := cell.removeMark(team)
+	if cleared && cell.Times > 0 {
+		cell.Times--
 	}
 
-	// Update phase rule tracking (consolidated)
-	if g.Rule == RulePhase && cleared {
-		if player == ColorRed && g.RedRowMarks[row] > 0 {
-			g.RedRowMarks[row]--
-		} else if player == ColorBlue && g.BlueRowMarks[row] > 0 {
-			g.BlueRowMarks[row]--
-		}
-		g.recheckPhaseRowUnlock(player)
-		g.recheckPhaseBingo()
+	if cleared {
+		g.rule().Unmarked(g, row, col, []Mark{{Team: team}})
 	}
 
 	return nil
 }
 
-// recheckPhaseRowUnlock checks if we need to rollback row unlock after clearing a mark
-func (g *Game) recheckPhaseRowUnlock(player PlayerColor) {
-	var unlockedRow *int
-	var rowMarks []int
-	if player == ColorRed {
-		unlockedRow = &g.RedUnlockedRow
-		rowMarks = g.RedRowMarks[:]
-	} else {
-		unlockedRow = &g.BlueUnlockedRow
-		rowMarks = g.BlueRowMarks[:]
-	}
-
-	// Check from the current unlocked row backwards
-	// To keep row N unlocked, row N-1 must have enough marks (>= threshold)
-	// If row N-1 doesn't meet the threshold, we need to rollback to N-1
-	for *unlockedRow > 0 {
-		// Check if the previous row still meets the threshold
-		prevRow := *unlockedRow - 1
-		if rowMarks[prevRow] >= g.PhaseConfig.UnlockThreshold {
-			// Previous row still meets threshold, no rollback needed
+// recheckPhaseRowUnlock checks if we need to rollback phase unlock after clearing a mark
+func (g *Game) recheckPhaseRowUnlock(team TeamID) {
+	state := g.TeamStates[team]
+	phases := g.phases()
+
+	// Check from the current unlocked phase backwards
+	// To keep phase N unlocked, phase N-1 must have enough marks (>= its threshold)
+	// If phase N-1 doesn't meet the threshold, we need to rollback to N-1
+	for state.UnlockedRow > 0 {
+		// Check if the previous phase still meets the threshold
+		prev := state.UnlockedRow - 1
+		if state.RowMarks[prev] >= phases[prev].UnlockThreshold {
+			// Previous phase still meets threshold, no rollback needed
 			break
 		}
 
-		// Previous row doesn't meet threshold, rollback
-		*unlockedRow--
+		// Previous phase doesn't meet threshold, rollback
+		state.UnlockedRow--
 	}
 }
 
-// recheckPhaseBingo rechecks Bingo status after a mark is cleared
-// If the current Bingo line is broken, clear it and try to find a new one
+// recheckPhaseBingo rechecks Bingo status after a mark is cleared or a cell's lock changes
+// If the current Bingo line is broken, clear it and try to find a new one;
+// without a Bingo, look for one too, as voiding a cell can complete a line
 func (g *Game) recheckPhaseBingo() {
-	if g.BingoAchiever == ColorNone {
-		return
-	}
-
 	// Check if current Bingo line is still valid
-	if g.isBingoLineValid(g.BingoLine, g.BingoAchiever) {
+	if g.BingoAchiever != TeamNone && g.isBingoLineValid(g.BingoLine, g.BingoAchiever) {
 		return // Bingo still valid
 	}
 
-	// Current Bingo is broken, clear it
-	g.BingoAchiever = ColorNone
+	// Current Bingo is broken (or there is none), clear it
+	g.BingoAchiever = TeamNone
 	g.BingoLine = -1
 
 	// Try to find a new Bingo (first one found wins)
@@ -692,36 +899,42 @@ func (g *Game) recheckPhaseBingo() {
 }
 
 // isBingoLineValid checks if a bingo line is still completely marked by the achiever
-// lineIndex: 0-4 = vertical columns, 5 = diagonal TL-BR, 6 = diagonal TR-BL
-func (g *Game) isBingoLineValid(lineIndex int, achiever PlayerColor) bool {
-	var positions [5][2]int
+// lineIndex: 0..N-1 = vertical columns, N = diagonal TL-BR, N+1 = diagonal TR-BL
+func (g *Game) isBingoLineValid(lineIndex int, achiever TeamID) bool {
+	size := g.Board.Size()
+	positions := make([][2]int, size)
 
 	switch {
-	case lineIndex >= 0 && lineIndex < 5:
+	case lineIndex >= 0 && lineIndex < size:
 		// Vertical line (column)
-		for i := 0; i < 5; i++ {
+		for i := 0; i < size; i++ {
 			positions[i] = [2]int{i, lineIndex}
 		}
-	case lineIndex == 5:
+	case lineIndex == size:
 		// Diagonal top-left to bottom-right
-		for i := 0; i < 5; i++ {
+		for i := 0; i < size; i++ {
 			positions[i] = [2]int{i, i}
 		}
-	case lineIndex == 6:
+	case lineIndex == size+1:
 		// Diagonal top-right to bottom-left
-		for i := 0; i < 5; i++ {
-			positions[i] = [2]int{i, 4 - i}
+		for i := 0; i < size; i++ {
+			positions[i] = [2]int{i, size - 1 - i}
 		}
 	default:
 		return false
 	}
 
-	// Check all positions in the line
+	// Check all positions in the line, void cells are free spaces
+	marked := false
 	for _, pos := range positions {
-		cell := g.Board.Cells[pos[0]][pos[1]]
-		if cell.MarkedBy != achiever && cell.SecondMark != achiever {
+		cell := &g.Board.Cells[pos[0]][pos[1]]
+		if cell.Void() {
+			continue
+		}
+		if !cell.HasMark(achiever) {
 			return false
 		}
+		marked = true
 	}
-	return true
+	return marked
 }
@@ -0,0 +1,108 @@
+package game
+
+import (
+	"testing"
+)
+
+func TestSmallBoardNormalWin(t *testing.T) {
+	g := NewGameWithSize(RuleNormal, 3)
+	g.Start()
+
+	g.MarkCell(0, 0, TeamRed)
+	g.MarkCell(1, 1, TeamRed)
+
+	if g.Status != StatusPlaying {
+		t.Fatalf("Game should still be playing, got: %v", g.Status)
+	}
+
+	// Third cell of the diagonal completes a line on a 3x3 board
+	g.MarkCell(2, 2, TeamRed)
+
+	if g.Winner == nil || g.Winner.Winner != TeamRed || g.Winner.Reason != WinReasonBingo {
+		t.Errorf("Red should win by bingo, got: %+v", g.Winner)
+	}
+
+	if err := g.MarkCell(3, 3, TeamBlue); err == nil {
+		t.Error("Marking outside a 3x3 board should fail")
+	}
+}
+
+func TestLargeBoardPhaseBingoLine(t *testing.T) {
+	g := NewGameWithSize(RulePhase, 7)
+	g.PhaseConfig.UnlockThreshold = 1
+	g.Start()
+
+	// Mark the anti-diagonal row by row, each mark unlocks the next row
+	for row := 0; row < 7; row++ {
+		if err := g.MarkCell(row, 6-row, TeamBlue); err != nil {
+			t.Fatalf("Mark row %d failed: %v", row, err)
+		}
+	}
+
+	if g.BingoAchiever != TeamBlue {
+		t.Errorf("Blue should achieve bingo, got: %v", g.BingoAchiever)
+	}
+	if g.BingoLine != 8 {
+		t.Errorf("Bingo line should be 8 (diagonal TR-BL on 7x7), got: %d", g.BingoLine)
+	}
+}
+
+func TestSetBoardSize(t *testing.T) {
+	g := NewGame(RuleNormal)
+
+	if err := g.SetBoardSize(2); err != ErrInvalidBoardSize {
+		t.Errorf("Expected ErrInvalidBoardSize, got: %v", err)
+	}
+
+	if err := g.SetBoardSize(7); err != nil {
+		t.Fatalf("SetBoardSize failed: %v", err)
+	}
+	if g.Board.Size() != 7 || len(g.TeamStates[TeamRed].RowMarks) != 7 || len(g.PhaseConfig.RowScores) != 7 {
+		t.Errorf("Board and phase tracking should follow size 7")
+	}
+
+	if err := g.SetAllCellTexts(make([]string, 25)); err == nil {
+		t.Error("SetAllCellTexts should require 49 texts on a 7x7 board")
+	}
+
+	g.Start()
+	if err := g.SetBoardSize(5); err == nil {
+		t.Error("SetBoardSize should fail after the game started")
+	}
+}
+
+func TestMultiBingo(t *testing.T) {
+	g := NewGameWithSize(RuleNormal, 3)
+	if err := g.SetLinesToWin(9); err == nil {
+		t.Error("More lines than a 3x3 board has should fail")
+	}
+	if err := g.SetLinesToWin(2); err != nil {
+		t.Fatalf("SetLinesToWin failed: %v", err)
+	}
+	g.Start()
+
+	// Top row for red
+	for col := 0; col < 3; col++ {
+		g.MarkCell(0, col, TeamRed)
+	}
+	if g.Status != StatusPlaying {
+		t.Fatal("One line should not win double bingo")
+	}
+
+	// Left column for red completes a second line
+	g.MarkCell(1, 0, TeamRed)
+	g.MarkCell(2, 0, TeamBlue)
+	if lines := g.CompletedLines(); len(lines[TeamRed]) != 1 || lines[TeamRed][0] != 0 {
+		t.Errorf("Red should only have row 0, got: %v", lines)
+	}
+
+	g.UnmarkCell(2, 0)
+	g.MarkCell(2, 0, TeamRed)
+	lines := g.CompletedLines()
+	if len(lines[TeamRed]) != 2 || lines[TeamRed][1] != 3 {
+		t.Errorf("Red should have row 0 and column 0 (index 3), got: %v", lines)
+	}
+	if g.Winner == nil || g.Winner.Winner != TeamRed {
+		t.Errorf("Red should win with two lines, got: %+v", g.Winner)
+	}
+}
@@ -0,0 +1,31 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCloneIsIndependent(t *testing.T) {
+	g := NewGame(RulePhase)
+	g.PhaseConfig.UnlockThreshold = 1
+	g.Start()
+	g.MarkCellBy(0, 0, Mark{Team: TeamRed, PlayerID: "u1"})
+	g.MarkCell(0, 0, TeamBlue)
+
+	c := g.Clone()
+	if !reflect.DeepEqual(g, c) {
+		t.Fatalf("Clone should equal the original:\n%+v\n%+v", g, c)
+	}
+
+	c.MarkCell(1, 0, TeamRed)
+	c.Board.Cells[0][0].Marks[0].PlayerID = "changed"
+	c.PhaseConfig.RowScores[0] = 100
+	c.Teams[0].Name = "changed"
+
+	if g.Board.Cells[1][0].HasMark(TeamRed) || g.TeamStates[TeamRed].RowMarks[1] != 0 {
+		t.Error("Marking the clone should not change the original")
+	}
+	if g.Board.Cells[0][0].Marks[0].PlayerID != "u1" || g.PhaseConfig.RowScores[0] == 100 || g.Teams[0].Name == "changed" {
+		t.Error("Clone should not share slices with the original")
+	}
+}
@@ -10,39 +10,39 @@ func TestPhaseRuleRowUnlockRollback(t *testing.T) {
 	g.Start()
 
 	// Row 0: mark 2 cells to unlock row 1
-	g.MarkCell(0, 0, ColorRed)
-	g.MarkCell(0, 1, ColorRed)
+	g.MarkCell(0, 0, TeamRed)
+	g.MarkCell(0, 1, TeamRed)
 
-	if g.RedUnlockedRow != 1 {
-		t.Errorf("Red unlocked row should be 1, got: %d", g.RedUnlockedRow)
+	if g.TeamStates[TeamRed].UnlockedRow != 1 {
+		t.Errorf("Red unlocked row should be 1, got: %d", g.TeamStates[TeamRed].UnlockedRow)
 	}
 
 	// Row 1: mark 2 cells to unlock row 2
-	g.MarkCell(1, 0, ColorRed)
-	g.MarkCell(1, 1, ColorRed)
+	g.MarkCell(1, 0, TeamRed)
+	g.MarkCell(1, 1, TeamRed)
 
-	if g.RedUnlockedRow != 2 {
-		t.Errorf("Red unlocked row should be 2, got: %d", g.RedUnlockedRow)
+	if g.TeamStates[TeamRed].UnlockedRow != 2 {
+		t.Errorf("Red unlocked row should be 2, got: %d", g.TeamStates[TeamRed].UnlockedRow)
 	}
 
 	// Clear one mark from row 1 (now only 1 mark, below threshold)
-	g.ClearCellMark(1, 1, ColorRed)
+	g.ClearCellMark(1, 1, TeamRed)
 
 	// Row should be rolled back to 1
-	if g.RedUnlockedRow != 1 {
-		t.Errorf("Red unlocked row should be rolled back to 1, got: %d", g.RedUnlockedRow)
+	if g.TeamStates[TeamRed].UnlockedRow != 1 {
+		t.Errorf("Red unlocked row should be rolled back to 1, got: %d", g.TeamStates[TeamRed].UnlockedRow)
 	}
 
 	// Clear one mark from row 0 (now only 1 mark, below threshold)
-	g.ClearCellMark(0, 1, ColorRed)
+	g.ClearCellMark(0, 1, TeamRed)
 
 	// Row should be rolled back to 0
-	if g.RedUnlockedRow != 0 {
-		t.Errorf("Red unlocked row should be rolled back to 0, got: %d", g.RedUnlockedRow)
+	if g.TeamStates[TeamRed].UnlockedRow != 0 {
+		t.Errorf("Red unlocked row should be rolled back to 0, got: %d", g.TeamStates[TeamRed].UnlockedRow)
 	}
 
 	// Try to mark row 1 again, should fail because it's locked
-	err := g.MarkCell(1, 2, ColorRed)
+	err := g.MarkCell(1, 2, TeamRed)
 	if err != ErrRowLocked {
 		t.Errorf("Expected ErrRowLocked, got: %v", err)
 	}
@@ -54,28 +54,28 @@ func TestPhaseRuleUnmarkCellRollback(t *testing.T) {
 	g.Start()
 
 	// Red player marks row 0
-	g.MarkCell(0, 0, ColorRed)
-	g.MarkCell(0, 1, ColorRed)
+	g.MarkCell(0, 0, TeamRed)
+	g.MarkCell(0, 1, TeamRed)
 
 	// Blue player marks row 0
-	g.MarkCell(0, 2, ColorBlue)
-	g.MarkCell(0, 3, ColorBlue)
+	g.MarkCell(0, 2, TeamBlue)
+	g.MarkCell(0, 3, TeamBlue)
 
-	if g.RedUnlockedRow != 1 {
-		t.Errorf("Red unlocked row should be 1, got: %d", g.RedUnlockedRow)
+	if g.TeamStates[TeamRed].UnlockedRow != 1 {
+		t.Errorf("Red unlocked row should be 1, got: %d", g.TeamStates[TeamRed].UnlockedRow)
 	}
-	if g.BlueUnlockedRow != 1 {
-		t.Errorf("Blue unlocked row should be 1, got: %d", g.BlueUnlockedRow)
+	if g.TeamStates[TeamBlue].UnlockedRow != 1 {
+		t.Errorf("Blue unlocked row should be 1, got: %d", g.TeamStates[TeamBlue].UnlockedRow)
 	}
 
 	// Use UnmarkCell (referee action) to clear a cell with red mark
 	g.UnmarkCell(0, 1) // This was a red mark
 
 	// Red should be rolled back, blue should still be unlocked
-	if g.RedUnlockedRow != 0 {
-		t.Errorf("Red unlocked row should be rolled back to 0, got: %d", g.RedUnlockedRow)
+	if g.TeamStates[TeamRed].UnlockedRow != 0 {
+		t.Errorf("Red unlocked row should be rolled back to 0, got: %d", g.TeamStates[TeamRed].UnlockedRow)
 	}
-	if g.BlueUnlockedRow != 1 {
-		t.Errorf("Blue unlocked row should still be 1, got: %d", g.BlueUnlockedRow)
+	if g.TeamStates[TeamBlue].UnlockedRow != 1 {
+		t.Errorf("Blue unlocked row should still be 1, got: %d", g.TeamStates[TeamBlue].UnlockedRow)
 	}
 }
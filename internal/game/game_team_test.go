@@ -0,0 +1,147 @@
+package game
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func threeTeams() []Team {
+	return []Team{
+		{ID: "red", Name: "Red", Color: "#e74c3c"},
+		{ID: "blue", Name: "Blue", Color: "#3498db"},
+		{ID: "green", Name: "Green", Color: "#2ecc71"},
+	}
+}
+
+func TestSetTeamsValidation(t *testing.T) {
+	g := NewGame(RuleNormal)
+
+	if err := g.SetTeams([]Team{{ID: "solo"}}); err == nil {
+		t.Error("A single team should be rejected")
+	}
+	if err := g.SetTeams([]Team{{ID: "a"}, {ID: "a"}}); err == nil {
+		t.Error("Duplicate team IDs should be rejected")
+	}
+	if err := g.SetTeams(threeTeams()); err != nil {
+		t.Fatalf("SetTeams failed: %v", err)
+	}
+	if len(g.TeamStates) != 3 {
+		t.Errorf("Expected 3 team states, got: %d", len(g.TeamStates))
+	}
+
+	g.Start()
+	if err := g.MarkCell(0, 0, "purple"); err != ErrUnknownTeam {
+		t.Errorf("Expected ErrUnknownTeam, got: %v", err)
+	}
+}
+
+func TestThreeTeamNormalWin(t *testing.T) {
+	g := NewGameWithSize(RuleNormal, 3)
+	g.SetTeams(threeTeams())
+	g.Start()
+
+	g.MarkCell(0, 0, "red")
+	g.MarkCell(1, 0, "blue")
+	g.MarkCell(0, 2, "green")
+	g.MarkCell(1, 2, "green")
+	g.MarkCell(2, 2, "green")
+
+	if g.Winner == nil || g.Winner.Winner != "green" {
+		t.Fatalf("Green should win, got: %+v", g.Winner)
+	}
+	if g.Winner.Scores["green"] != 3 || g.Winner.Scores["red"] != 1 || g.Winner.Scores["blue"] != 1 {
+		t.Errorf("Unexpected scores: %v", g.Winner.Scores)
+	}
+}
+
+func TestThreeTeamBlackoutMarks(t *testing.T) {
+	g := NewGame(RuleBlackout)
+	g.SetTeams(threeTeams())
+	g.Start()
+
+	g.MarkCell(2, 2, "green")
+	g.MarkCell(2, 2, "red")
+	g.MarkCell(2, 2, "blue")
+
+	cell := g.Board.Cells[2][2]
+	if cell.Times != 3 || cell.MarkedBy() != "green" || cell.SecondMark() != "red" {
+		t.Errorf("Unexpected cell marks: %+v", cell)
+	}
+
+	g.ClearCellMark(2, 2, "green")
+	if g.Board.Cells[2][2].MarkedBy() != "red" {
+		t.Errorf("Second mark should be promoted, got: %v", g.Board.Cells[2][2].MarkedBy())
+	}
+}
+
+func TestPhaseSettleWaitsForAllTeams(t *testing.T) {
+	g := NewGameWithSize(RulePhase, 3)
+	g.SetTeams(threeTeams())
+	g.PhaseConfig.UnlockThreshold = 1
+	g.Start()
+
+	g.MarkCell(0, 0, "red")
+	g.MarkCell(1, 0, "red")
+	g.MarkCell(2, 0, "red")
+	g.MarkCell(2, 1, "red")
+
+	if err := g.Settle("red"); err != nil {
+		t.Fatalf("Red should be able to settle: %v", err)
+	}
+	g.Settle("blue")
+	if g.Status != StatusPlaying {
+		t.Errorf("Game should continue until every team settled, got: %v", g.Status)
+	}
+
+	g.Settle("green")
+	if g.Status != StatusFinished || g.Winner.Winner != "red" {
+		t.Errorf("Red should win after all teams settled, got: %+v", g.Winner)
+	}
+}
+
+func TestLegacyGameUnmarshal(t *testing.T) {
+	data := `{"board":{"cells":[[{"marked_by":1,"second_mark":2,"times":1,"text":"a"},{"marked_by":0}],[{"marked_by":2},{}]]},` +
+		`"rule":2,"phase_config":{"unlock_threshold":1},"bingo_achiever":0,"first_settler":1}`
+
+	var g Game
+	if err := json.Unmarshal([]byte(data), &g); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	g.Normalize()
+
+	cell := g.Board.Cells[0][0]
+	if cell.MarkedBy() != TeamRed || cell.SecondMark() != TeamBlue || cell.Text != "a" {
+		t.Errorf("Legacy marks not converted: %+v", cell)
+	}
+	if g.FirstSettler != TeamRed {
+		t.Errorf("Legacy first settler not converted: %v", g.FirstSettler)
+	}
+	if len(g.Teams) != 2 || g.TeamStates[TeamBlue].RowMarks[1] != 1 || g.TeamStates[TeamRed].UnlockedRow != 1 {
+		t.Errorf("Team states not rebuilt: %+v %+v", g.TeamStates[TeamRed], g.TeamStates[TeamBlue])
+	}
+}
+
+func TestMarkRecordsPlayer(t *testing.T) {
+	g := NewGame(RuleBlackout)
+	g.Start()
+
+	if err := g.MarkCellBy(0, 0, Mark{Team: TeamRed, PlayerID: "u1", PlayerName: "alice"}); err != nil {
+		t.Fatalf("MarkCellBy failed: %v", err)
+	}
+	if err := g.MarkCellBy(0, 0, Mark{Team: TeamBlue, PlayerID: "u2", PlayerName: "bob"}); err != nil {
+		t.Fatalf("MarkCellBy failed: %v", err)
+	}
+	if err := g.MarkCellBy(0, 0, Mark{Team: TeamRed, PlayerID: "u3", PlayerName: "carol"}); err == nil {
+		t.Error("Second member of the same team should not mark the cell again")
+	}
+
+	marks := g.Board.Cells[0][0].Marks
+	if len(marks) != 2 || marks[0].PlayerID != "u1" || marks[1].PlayerName != "bob" {
+		t.Errorf("Marks should record who made them, got: %+v", marks)
+	}
+
+	g.ClearCellMark(0, 0, TeamRed)
+	if marks := g.Board.Cells[0][0].Marks; len(marks) != 1 || marks[0].PlayerID != "u2" {
+		t.Errorf("Remaining mark should keep its player, got: %+v", marks)
+	}
+}
@@ -11,54 +11,54 @@ func TestPhaseRuleSecondSettlerNoCondition(t *testing.T) {
 
 	// First, unlock all rows for red player
 	// Row 0: mark 2 cells to unlock row 1
-	g.MarkCell(0, 0, ColorRed)
-	g.MarkCell(0, 1, ColorRed)
+	g.MarkCell(0, 0, TeamRed)
+	g.MarkCell(0, 1, TeamRed)
 	// Row 1: mark 2 cells to unlock row 2
-	g.MarkCell(1, 0, ColorRed)
-	g.MarkCell(1, 1, ColorRed)
+	g.MarkCell(1, 0, TeamRed)
+	g.MarkCell(1, 1, TeamRed)
 	// Row 2: mark 2 cells to unlock row 3
-	g.MarkCell(2, 0, ColorRed)
-	g.MarkCell(2, 1, ColorRed)
+	g.MarkCell(2, 0, TeamRed)
+	g.MarkCell(2, 1, TeamRed)
 	// Row 3: mark 2 cells to unlock row 4
-	g.MarkCell(3, 0, ColorRed)
-	g.MarkCell(3, 1, ColorRed)
+	g.MarkCell(3, 0, TeamRed)
+	g.MarkCell(3, 1, TeamRed)
 
 	// Now row 4 is unlocked, mark 2 cells in row 5 (index 4) for red player
-	g.MarkCell(4, 0, ColorRed)
-	g.MarkCell(4, 1, ColorRed)
+	g.MarkCell(4, 0, TeamRed)
+	g.MarkCell(4, 1, TeamRed)
 
 	// Red player should be able to settle (meets conditions)
-	err := g.Settle(ColorRed)
+	err := g.Settle(TeamRed)
 	if err != nil {
 		t.Errorf("Red player should be able to settle, got error: %v", err)
 	}
 
-	if !g.RedSettled {
+	if !g.TeamStates[TeamRed].Settled {
 		t.Error("Red player should be settled")
 	}
 
-	if g.FirstSettler != ColorRed {
+	if g.FirstSettler != TeamRed {
 		t.Errorf("First settler should be red, got: %v", g.FirstSettler)
 	}
 
 	// Unlock all rows for blue player
-	g.MarkCell(0, 2, ColorBlue)
-	g.MarkCell(0, 3, ColorBlue)
-	g.MarkCell(1, 2, ColorBlue)
-	g.MarkCell(1, 3, ColorBlue)
-	g.MarkCell(2, 2, ColorBlue)
-	g.MarkCell(2, 3, ColorBlue)
-	g.MarkCell(3, 2, ColorBlue)
-	g.MarkCell(3, 3, ColorBlue)
+	g.MarkCell(0, 2, TeamBlue)
+	g.MarkCell(0, 3, TeamBlue)
+	g.MarkCell(1, 2, TeamBlue)
+	g.MarkCell(1, 3, TeamBlue)
+	g.MarkCell(2, 2, TeamBlue)
+	g.MarkCell(2, 3, TeamBlue)
+	g.MarkCell(3, 2, TeamBlue)
+	g.MarkCell(3, 3, TeamBlue)
 
 	// Blue player has NOT marked any cells in row 5
 	// But should still be able to settle because red already settled
-	err = g.Settle(ColorBlue)
+	err = g.Settle(TeamBlue)
 	if err != nil {
 		t.Errorf("Blue player should be able to settle without conditions after red settled, got error: %v", err)
 	}
 
-	if !g.BlueSettled {
+	if !g.TeamStates[TeamBlue].Settled {
 		t.Error("Blue player should be settled")
 	}
 
@@ -74,29 +74,29 @@ func TestPhaseRuleFirstSettlerNeedsCondition(t *testing.T) {
 	g.Start()
 
 	// Unlock all rows for red player
-	g.MarkCell(0, 0, ColorRed)
-	g.MarkCell(0, 1, ColorRed)
-	g.MarkCell(1, 0, ColorRed)
-	g.MarkCell(1, 1, ColorRed)
-	g.MarkCell(2, 0, ColorRed)
-	g.MarkCell(2, 1, ColorRed)
-	g.MarkCell(3, 0, ColorRed)
-	g.MarkCell(3, 1, ColorRed)
+	g.MarkCell(0, 0, TeamRed)
+	g.MarkCell(0, 1, TeamRed)
+	g.MarkCell(1, 0, TeamRed)
+	g.MarkCell(1, 1, TeamRed)
+	g.MarkCell(2, 0, TeamRed)
+	g.MarkCell(2, 1, TeamRed)
+	g.MarkCell(3, 0, TeamRed)
+	g.MarkCell(3, 1, TeamRed)
 
 	// Red player has NOT marked enough cells in row 5 (only 1 cell)
-	g.MarkCell(4, 0, ColorRed)
+	g.MarkCell(4, 0, TeamRed)
 
 	// Should NOT be able to settle
-	err := g.Settle(ColorRed)
+	err := g.Settle(TeamRed)
 	if err == nil {
 		t.Error("Red player should NOT be able to settle without meeting conditions")
 	}
 
-	if g.RedSettled {
+	if g.TeamStates[TeamRed].Settled {
 		t.Error("Red player should NOT be settled")
 	}
 
-	if g.FirstSettler != ColorNone {
+	if g.FirstSettler != TeamNone {
 		t.Errorf("First settler should be none, got: %v", g.FirstSettler)
 	}
 }
@@ -0,0 +1,446 @@
+package game
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"iter"
+	"math/rand/v2"
+	"slices"
+	"strings"
+)
+
+// Goal is an entry of a goal pool used to generate boards
+// At most one goal of an exclusion group goes on a board, and goals sharing
+// a tag (anti-synergy) never share a row, column or diagonal
+type Goal struct {
+	Text       string   `json:"text"`
+	Difficulty int      `json:"difficulty"` // Tier, 1 is the easiest
+	Group      string   `json:"group,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	Target     int      `json:"target,omitempty"` // Count to reach for "collect N" goals, 0 for none
+}
+
+const (
+	MaxGoalPool = 2000      // Most goals a pool can hold
+	MaxSeed     = 1<<53 - 1 // Largest seed, so seeds stay exact as JSON numbers
+
+	maxGeneratorSteps    = 1000000 // Candidates tried before giving up on a constrained pool
+	maxGeneratorAttempts = 200     // Restarts with a fresh random order, sharing the steps above
+)
+
+// generatorStream is the second PCG word, fixed so that a seed alone defines a board
+const generatorStream = 0x62696e676f73796e // "bingosyn"
+
+var (
+	ErrInvalidGoalPool  = errors.New("invalid goal pool")
+	ErrGoalPoolTooSmall = errors.New("goal pool is smaller than the board")
+	ErrInvalidSeed      = errors.New("seed must be between 0 and 2^53-1")
+	ErrExclusionGroup   = errors.New("exclusion group used more than once")
+	ErrAntiSynergy      = errors.New("anti-synergy goals share a line")
+	ErrUnsatisfiable    = errors.New("no board satisfies the goal pool constraints")
+)
+
+// RandomSeed returns a random seed short enough to be shared by hand
+func RandomSeed() int64 {
+	return rand.Int64N(1_000_000)
+}
+
+// ValidateGoalPool checks that every goal has a text and a difficulty, and that texts are unique
+func ValidateGoalPool(goals []Goal) error {
+	if len(goals) == 0 || len(goals) > MaxGoalPool {
+		return fmt.Errorf("%w: need 1 to %d goals", ErrInvalidGoalPool, MaxGoalPool)
+	}
+
+	seen := make(map[string]bool, len(goals))
+	for i, goal := range goals {
+		if strings.TrimSpace(goal.Text) == "" {
+			return fmt.Errorf("%w: goal %d has no text", ErrInvalidGoalPool, i+1)
+		}
+		if goal.Difficulty < 1 {
+			return fmt.Errorf("%w: goal %q needs a difficulty of at least 1", ErrInvalidGoalPool, goal.Text)
+		}
+		if goal.Target < 0 || goal.Target > MaxCellTarget {
+			return fmt.Errorf("%w: goal %q: %w", ErrInvalidGoalPool, goal.Text, ErrInvalidTarget)
+		}
+		if seen[goal.Text] {
+			return fmt.Errorf("%w: duplicate goal %q", ErrInvalidGoalPool, goal.Text)
+		}
+		seen[goal.Text] = true
+	}
+	return nil
+}
+
+// GenerateBoard picks size*size goals from the pool for a seed, returning texts in row-major order
+// Like SRL bingo generators, each cell gets a difficulty rank from a magic square, so every row,
+// column and diagonal has the same total rank. The same pool, size and seed give the same board.
+func GenerateBoard(goals []Goal, size int, seed int64) ([]string, error) {
+	if !ValidBoardSize(size) {
+		return nil, ErrInvalidBoardSize
+	}
+	if seed < 0 || seed > MaxSeed {
+		return nil, ErrInvalidSeed
+	}
+	if err := ValidateGoalPool(goals); err != nil {
+		return nil, err
+	}
+	cells := size * size
+	if n := distinctGoals(goals); n < cells {
+		return nil, fmt.Errorf("%w: need %d goals from different exclusion groups, have %d", ErrGoalPoolTooSmall, cells, n)
+	}
+
+	rng := rand.New(rand.NewPCG(uint64(seed), generatorStream))
+
+	// Order goals by difficulty, in random order within a tier
+	order := rng.Perm(len(goals))
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(goals[a].Difficulty, goals[b].Difficulty)
+	})
+
+	gen := &generator{
+		goals: goals,
+		size:  size,
+		order: order,
+		ranks: shuffleMagicSquare(magicSquare(size), rng),
+		board: make([]int, cells),
+		rng:   rng,
+	}
+	// Backtracking can get stuck deep in a tightly constrained pool, where starting
+	// over with another random order usually finds a board quickly
+	for attempt := 1; !gen.fill(); attempt++ {
+		if attempt == maxGeneratorAttempts || gen.steps >= maxGeneratorSteps {
+			return nil, ErrUnsatisfiable
+		}
+	}
+
+	texts := make([]string, cells)
+	for pos, goal := range gen.board {
+		texts[pos] = goals[goal].Text
+	}
+	return texts, nil
+}
+
+// CheckBoard checks cell texts in row-major order against the constraints of a goal pool
+// Texts that are not in the pool are not constrained
+func CheckBoard(goals []Goal, size int, texts []string) error {
+	if len(texts) != size*size {
+		return fmt.Errorf("must provide exactly %d texts", size*size)
+	}
+
+	byText := make(map[string]*Goal, len(goals))
+	for i := range goals {
+		byText[goals[i].Text] = &goals[i]
+	}
+
+	groups := make(map[string]string) // Group -> text of the goal using it
+	for _, text := range texts {
+		goal, ok := byText[text]
+		if !ok || goal.Group == "" {
+			continue
+		}
+		if other, ok := groups[goal.Group]; ok {
+			return fmt.Errorf("%w: %q and %q are both in group %q", ErrExclusionGroup, other, text, goal.Group)
+		}
+		groups[goal.Group] = text
+	}
+
+	for _, line := range boardLines(size) {
+		for i, a := range line {
+			for _, b := range line[i+1:] {
+				ga, gb := byText[texts[a]], byText[texts[b]]
+				if ga == nil || gb == nil {
+					continue
+				}
+				if tag, ok := sharedTag(ga, gb); ok {
+					return fmt.Errorf("%w: %q and %q are both tagged %q in %s",
+						ErrAntiSynergy, ga.Text, gb.Text, tag, lineName(line, size))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// generator places goals cell by cell, backtracking when a cell has no goal left that fits
+type generator struct {
+	goals  []Goal
+	size   int
+	order  []int // Goal indexes by difficulty
+	ranks  []int // Difficulty rank per cell
+	board  []int // Goal index per cell
+	used   []bool
+	groups map[string]bool
+	rng    *rand.Rand
+	steps  int // Candidates tried over all attempts
+	budget int // Steps at which the current attempt gives up
+}
+
+// fill starts an attempt at a full board, returning false if it ran out of steps
+func (gen *generator) fill() bool {
+	gen.used = make([]bool, len(gen.goals))
+	gen.groups = make(map[string]bool)
+	gen.budget = min(gen.steps+maxGeneratorSteps/maxGeneratorAttempts, maxGeneratorSteps)
+	return gen.place(0)
+}
+
+// place places goals from cell pos on, returning false if no placement works
+func (gen *generator) place(pos int) bool {
+	if pos == len(gen.board) {
+		return true
+	}
+	for goal := range gen.candidates(gen.ranks[pos]) {
+		gen.steps++
+		if gen.steps >= gen.budget {
+			return false
+		}
+		if !gen.fits(pos, goal) {
+			continue
+		}
+
+		gen.board[pos] = goal
+		gen.used[goal] = true
+		if group := gen.goals[goal].Group; group != "" {
+			gen.groups[group] = true
+		}
+		if gen.place(pos + 1) {
+			return true
+		}
+		gen.used[goal] = false
+		if group := gen.goals[goal].Group; group != "" {
+			gen.groups[group] = false
+		}
+	}
+	return false
+}
+
+// candidates yields goals for a cell of the given rank: the rank's own slice of the
+// difficulty order first, then the slices next to it, each in random order
+// Slices do not overlap, so without constraints every rank keeps to its own
+func (gen *generator) candidates(rank int) iter.Seq[int] {
+	cells := len(gen.board)
+	return func(yield func(int) bool) {
+		slice := func(r int) bool {
+			if r < 1 || r > cells {
+				return true
+			}
+			lo := (r - 1) * len(gen.order) / cells
+			hi := r * len(gen.order) / cells
+			for _, i := range gen.rng.Perm(hi - lo) {
+				if !yield(gen.order[lo+i]) {
+					return false
+				}
+			}
+			return true
+		}
+
+		if !slice(rank) {
+			return
+		}
+		for d := 1; d < cells; d++ {
+			if !slice(rank-d) || !slice(rank+d) {
+				return
+			}
+		}
+	}
+}
+
+// fits reports whether the goal can go on cell pos given the goals placed before it
+func (gen *generator) fits(pos, goal int) bool {
+	g := &gen.goals[goal]
+	if gen.used[goal] || (g.Group != "" && gen.groups[g.Group]) {
+		return false
+	}
+	if len(g.Tags) == 0 {
+		return true
+	}
+
+	row, col := pos/gen.size, pos%gen.size
+	for other := 0; other < pos; other++ {
+		r, c := other/gen.size, other%gen.size
+		sameLine := r == row || c == col ||
+			(r == c && row == col) ||
+			(r+c == gen.size-1 && row+col == gen.size-1)
+		if !sameLine {
+			continue
+		}
+		if _, ok := sharedTag(g, &gen.goals[gen.board[other]]); ok {
+			return false
+		}
+	}
+	return true
+}
+
+// distinctGoals counts the goals that can go on one board together:
+// goals without a group plus one per exclusion group
+func distinctGoals(goals []Goal) int {
+	n := 0
+	groups := make(map[string]bool)
+	for _, goal := range goals {
+		if goal.Group == "" {
+			n++
+		} else if !groups[goal.Group] {
+			groups[goal.Group] = true
+			n++
+		}
+	}
+	return n
+}
+
+// sharedTag returns a tag both goals carry
+func sharedTag(a, b *Goal) (string, bool) {
+	for _, tag := range a.Tags {
+		if slices.Contains(b.Tags, tag) {
+			return tag, true
+		}
+	}
+	return "", false
+}
+
+// boardLines returns the cell positions (row-major) of every row, column and diagonal
+func boardLines(size int) [][]int {
+	lines := make([][]int, 0, 2*size+2)
+	diag := make([]int, size)
+	anti := make([]int, size)
+	for i := 0; i < size; i++ {
+		row := make([]int, size)
+		col := make([]int, size)
+		for j := 0; j < size; j++ {
+			row[j] = i*size + j
+			col[j] = j*size + i
+		}
+		lines = append(lines, row, col)
+		diag[i] = i*size + i
+		anti[i] = i*size + size - 1 - i
+	}
+	return append(lines, diag, anti)
+}
+
+// lineName describes a line from boardLines for error messages
+func lineName(line []int, size int) string {
+	switch {
+	case line[1]-line[0] == 1:
+		return fmt.Sprintf("row %d", line[0]/size+1)
+	case line[1]-line[0] == size:
+		return fmt.Sprintf("column %d", line[0]+1)
+	case line[0] == 0:
+		return "the \\ diagonal"
+	}
+	return "the / diagonal"
+}
+
+// magicSquare returns an n x n square of 1..n*n whose rows, columns and diagonals all have the same sum
+func magicSquare(n int) [][]int {
+	switch {
+	case n%2 == 1:
+		return oddMagicSquare(n)
+	case n%4 == 0:
+		return doublyEvenMagicSquare(n)
+	default:
+		return singlyEvenMagicSquare(n)
+	}
+}
+
+func newSquare(n int) [][]int {
+	m := make([][]int, n)
+	for i := range m {
+		m[i] = make([]int, n)
+	}
+	return m
+}
+
+// oddMagicSquare builds a magic square of odd order with the Siamese method
+func oddMagicSquare(n int) [][]int {
+	m := newSquare(n)
+	i, j := 0, n/2
+	for v := 1; v <= n*n; v++ {
+		m[i][j] = v
+		ni, nj := (i-1+n)%n, (j+1)%n
+		if m[ni][nj] != 0 {
+			ni, nj = (i+1)%n, j
+		}
+		i, j = ni, nj
+	}
+	return m
+}
+
+// doublyEvenMagicSquare builds a magic square of order 4k by complementing both diagonals of each 4x4 block
+func doublyEvenMagicSquare(n int) [][]int {
+	m := newSquare(n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			v := i*n + j + 1
+			if i%4 == j%4 || i%4+j%4 == 3 {
+				v = n*n + 1 - v
+			}
+			m[i][j] = v
+		}
+	}
+	return m
+}
+
+// singlyEvenMagicSquare builds a magic square of order 4k+2 with Strachey's method
+func singlyEvenMagicSquare(n int) [][]int {
+	half := n / 2
+	quarter := half * half
+	sub := oddMagicSquare(half)
+
+	m := newSquare(n)
+	for i := 0; i < half; i++ {
+		for j := 0; j < half; j++ {
+			v := sub[i][j]
+			m[i][j] = v
+			m[i+half][j+half] = v + quarter
+			m[i][j+half] = v + 2*quarter
+			m[i+half][j] = v + 3*quarter
+		}
+	}
+
+	k := (n - 2) / 4
+	for i := 0; i < half; i++ {
+		// Left k columns swap between the top and bottom halves, the middle row shifted right by one
+		for j := 0; j < k; j++ {
+			col := j
+			if i == half/2 {
+				col = j + 1
+			}
+			m[i][col], m[i+half][col] = m[i+half][col], m[i][col]
+		}
+		// Right k-1 columns swap as well
+		for col := n - k + 1; col < n; col++ {
+			m[i][col], m[i+half][col] = m[i+half][col], m[i][col]
+		}
+	}
+	return m
+}
+
+// shuffleMagicSquare flattens the square after a random rotation or reflection and
+// possibly taking the complement, all of which keep every line sum equal
+func shuffleMagicSquare(m [][]int, rng *rand.Rand) []int {
+	n := len(m)
+	transpose := rng.IntN(2) == 1
+	flipRows := rng.IntN(2) == 1
+	flipCols := rng.IntN(2) == 1
+	complement := rng.IntN(2) == 1
+
+	out := make([]int, n*n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			si, sj := i, j
+			if transpose {
+				si, sj = sj, si
+			}
+			if flipRows {
+				si = n - 1 - si
+			}
+			if flipCols {
+				sj = n - 1 - sj
+			}
+			v := m[si][sj]
+			if complement {
+				v = n*n + 1 - v
+			}
+			out[i*n+j] = v
+		}
+	}
+	return out
+}
@@ -0,0 +1,190 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// rankedPool returns n goals with difficulties 1..n
+func rankedPool(n int) []Goal {
+	goals := make([]Goal, n)
+	for i := range goals {
+		goals[i] = Goal{Text: fmt.Sprintf("goal %d", i+1), Difficulty: i + 1}
+	}
+	return goals
+}
+
+func TestMagicSquares(t *testing.T) {
+	for n := MinBoardSize; n <= MaxBoardSize; n++ {
+		m := magicSquare(n)
+		want := n * (n*n + 1) / 2
+
+		seen := make(map[int]bool)
+		diag, anti := 0, 0
+		for i := 0; i < n; i++ {
+			row, col := 0, 0
+			for j := 0; j < n; j++ {
+				row += m[i][j]
+				col += m[j][i]
+				seen[m[i][j]] = true
+			}
+			if row != want || col != want {
+				t.Errorf("size %d: row/col %d sums to %d/%d, want %d", n, i, row, col, want)
+			}
+			diag += m[i][i]
+			anti += m[i][n-1-i]
+		}
+		if diag != want || anti != want {
+			t.Errorf("size %d: diagonals sum to %d/%d, want %d", n, diag, anti, want)
+		}
+		if len(seen) != n*n || seen[0] {
+			t.Errorf("size %d: square should hold each of 1..%d once", n, n*n)
+		}
+	}
+}
+
+func TestGenerateBoardBalanced(t *testing.T) {
+	for _, size := range []int{3, 5, 6} {
+		goals := rankedPool(size * size)
+		difficulty := make(map[string]int)
+		for _, goal := range goals {
+			difficulty[goal.Text] = goal.Difficulty
+		}
+
+		texts, err := GenerateBoard(goals, size, 12345)
+		if err != nil {
+			t.Fatalf("GenerateBoard failed: %v", err)
+		}
+
+		want := size * (size*size + 1) / 2
+		for _, line := range boardLines(size) {
+			sum := 0
+			for _, pos := range line {
+				sum += difficulty[texts[pos]]
+			}
+			if sum != want {
+				t.Errorf("size %d: line %v has difficulty %d, want %d", size, line, sum, want)
+			}
+		}
+	}
+}
+
+func TestGenerateBoardSeeded(t *testing.T) {
+	goals := rankedPool(100)
+
+	a, _ := GenerateBoard(goals, 5, 42)
+	b, _ := GenerateBoard(goals, 5, 42)
+	c, _ := GenerateBoard(goals, 5, 43)
+	if !slices.Equal(a, b) {
+		t.Error("Same seed should give the same board")
+	}
+	if slices.Equal(a, c) {
+		t.Error("Different seeds should give different boards")
+	}
+
+	sorted := slices.Clone(a)
+	slices.Sort(sorted)
+	if len(slices.Compact(sorted)) != 25 {
+		t.Errorf("Board should not repeat goals: %v", a)
+	}
+}
+
+func TestGenerateBoardErrors(t *testing.T) {
+	if _, err := GenerateBoard(rankedPool(24), 5, 1); !errors.Is(err, ErrGoalPoolTooSmall) {
+		t.Errorf("Small pool should fail with ErrGoalPoolTooSmall, got: %v", err)
+	}
+	if _, err := GenerateBoard(rankedPool(25), 5, -1); !errors.Is(err, ErrInvalidSeed) {
+		t.Errorf("Negative seed should fail with ErrInvalidSeed, got: %v", err)
+	}
+
+	dup := append(rankedPool(25), Goal{Text: "goal 1", Difficulty: 1})
+	if err := ValidateGoalPool(dup); !errors.Is(err, ErrInvalidGoalPool) {
+		t.Errorf("Duplicate goal should fail validation, got: %v", err)
+	}
+	if err := ValidateGoalPool([]Goal{{Text: "x"}}); !errors.Is(err, ErrInvalidGoalPool) {
+		t.Errorf("Goal without difficulty should fail validation, got: %v", err)
+	}
+}
+
+func TestGameGenerateBoardRecordsSeed(t *testing.T) {
+	g := NewGame(RuleNormal)
+	if err := g.GenerateBoard(rankedPool(30), 7); err != nil {
+		t.Fatalf("GenerateBoard failed: %v", err)
+	}
+	if g.Seed == nil || *g.Seed != 7 || g.Board.Cells[0][0].Text == "" {
+		t.Errorf("Generated board should record its seed, got: %v", g.Seed)
+	}
+
+	g.SetCellText(0, 0, "edited")
+	if g.Seed != nil {
+		t.Error("Editing a cell should drop the seed")
+	}
+}
+
+func TestGenerateBoardConstraints(t *testing.T) {
+	goals := rankedPool(60)
+	for i := range goals {
+		goals[i].Group = fmt.Sprintf("group %d", i/2) // Pairs, 30 groups for 25 cells
+		goals[i].Tags = []string{fmt.Sprintf("tag %d", i%6)}
+	}
+
+	for seed := int64(0); seed < 20; seed++ {
+		texts, err := GenerateBoard(goals, 5, seed)
+		if err != nil {
+			t.Fatalf("seed %d: GenerateBoard failed: %v", seed, err)
+		}
+		if err := CheckBoard(goals, 5, texts); err != nil {
+			t.Errorf("seed %d: generated board breaks a constraint: %v", seed, err)
+		}
+	}
+}
+
+func TestGenerateBoardUnsatisfiable(t *testing.T) {
+	// 25 goals but only 20 groups
+	goals := rankedPool(25)
+	for i := range goals[:10] {
+		goals[i].Group = fmt.Sprintf("group %d", i/2)
+	}
+	if _, err := GenerateBoard(goals, 5, 1); !errors.Is(err, ErrGoalPoolTooSmall) {
+		t.Errorf("Pool with too few groups should fail with ErrGoalPoolTooSmall, got: %v", err)
+	}
+
+	// Every goal shares a tag, no line can be filled
+	goals = rankedPool(30)
+	for i := range goals {
+		goals[i].Tags = []string{"same"}
+	}
+	if _, err := GenerateBoard(goals, 3, 1); !errors.Is(err, ErrUnsatisfiable) {
+		t.Errorf("Pool without a valid board should fail with ErrUnsatisfiable, got: %v", err)
+	}
+}
+
+func TestCheckBoard(t *testing.T) {
+	goals := []Goal{
+		{Text: "a", Difficulty: 1, Group: "g"},
+		{Text: "b", Difficulty: 1, Group: "g"},
+		{Text: "c", Difficulty: 1, Tags: []string{"t"}},
+		{Text: "d", Difficulty: 1, Tags: []string{"t"}},
+	}
+	board := func(cells map[int]string) []string {
+		texts := make([]string, 9)
+		for pos, text := range cells {
+			texts[pos] = text
+		}
+		return texts
+	}
+
+	if err := CheckBoard(goals, 3, board(map[int]string{0: "a", 1: "b"})); !errors.Is(err, ErrExclusionGroup) {
+		t.Errorf("Two goals of a group should fail with ErrExclusionGroup, got: %v", err)
+	}
+	err := CheckBoard(goals, 3, board(map[int]string{0: "c", 8: "d"}))
+	if !errors.Is(err, ErrAntiSynergy) || !strings.Contains(err.Error(), "diagonal") {
+		t.Errorf("Tagged goals on a diagonal should fail with ErrAntiSynergy, got: %v", err)
+	}
+	if err := CheckBoard(goals, 3, board(map[int]string{0: "c", 5: "d", 1: "free text"})); err != nil {
+		t.Errorf("Board keeping the constraints should pass, got: %v", err)
+	}
+}
@@ -0,0 +1,215 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+)
+
+const (
+	MaxScoreOffset = 1000             // Largest score offset either way
+	MaxStartDelay  = 30 * time.Minute // Longest start delay of a team
+)
+
+var (
+	ErrInvalidHandicap = errors.New("invalid handicap")
+	ErrNoCenter        = errors.New("free center needs a board with an odd size")
+	ErrStartDelay      = errors.New("your team cannot mark yet")
+)
+
+// Handicap is a team's head start or penalty in a handicap match
+type Handicap struct {
+	Team        TeamID        `json:"team"`
+	Cells       [][2]int      `json:"cells,omitempty"`        // [row, col], marked for the team in this order at the start
+	ScoreOffset int           `json:"score_offset,omitempty"` // Added to the team's score or mark count in results
+	StartDelay  time.Duration `json:"start_delay,omitempty"`  // How long after the start the team has to wait to mark
+}
+
+// empty reports whether the handicap changes nothing
+func (h *Handicap) empty() bool {
+	return len(h.Cells) == 0 && h.ScoreOffset == 0 && h.StartDelay == 0
+}
+
+// SetFreeCenter sets whether the center cell is a free space, voided when the game starts (not while playing)
+func (g *Game) SetFreeCenter(free bool) error {
+	if g.Status == StatusPlaying || g.Status == StatusCountdown {
+		return errors.New("cannot change free center while playing")
+	}
+	if free && g.Board.Size()%2 == 0 {
+		return ErrNoCenter
+	}
+	g.FreeCenter = free
+	return nil
+}
+
+// SetHandicaps sets the teams' handicaps, one entry per team at most (not while playing)
+// A cell can only be given to one team; whether the rule accepts the pre-marked cells
+// is checked right away on a waiting game and again at the start
+func (g *Game) SetHandicaps(handicaps []Handicap) error {
+	if g.Status == StatusPlaying || g.Status == StatusCountdown {
+		return errors.New("cannot change handicaps while playing")
+	}
+
+	var seen [][2]int
+	var result []Handicap
+	for i, h := range handicaps {
+		if !g.HasTeam(h.Team) {
+			return fmt.Errorf("%w: unknown team %q", ErrInvalidHandicap, h.Team)
+		}
+		if slices.ContainsFunc(handicaps[:i], func(other Handicap) bool { return other.Team == h.Team }) {
+			return fmt.Errorf("%w: team %q is listed more than once", ErrInvalidHandicap, h.Team)
+		}
+		if h.ScoreOffset < -MaxScoreOffset || h.ScoreOffset > MaxScoreOffset {
+			return fmt.Errorf("%w: score offset must be between -%d and %d", ErrInvalidHandicap, MaxScoreOffset, MaxScoreOffset)
+		}
+		if h.StartDelay < 0 || h.StartDelay > MaxStartDelay {
+			return fmt.Errorf("%w: start delay must be between 0 and %s", ErrInvalidHandicap, MaxStartDelay)
+		}
+		for _, pos := range h.Cells {
+			if !g.Board.InBounds(pos[0], pos[1]) {
+				return fmt.Errorf("%w: %w", ErrInvalidHandicap, ErrInvalidPosition)
+			}
+			if slices.Contains(seen, pos) {
+				return fmt.Errorf("%w: cell %d,%d is given more than once", ErrInvalidHandicap, pos[0]+1, pos[1]+1)
+			}
+			seen = append(seen, pos)
+		}
+		if !h.empty() {
+			h.Cells = slices.Clone(h.Cells)
+			result = append(result, h)
+		}
+	}
+
+	prev := g.Handicaps
+	g.Handicaps = result
+	if g.Status == StatusWaiting {
+		if err := g.checkStartCells(); err != nil {
+			g.Handicaps = prev
+			return err
+		}
+	}
+	return nil
+}
+
+// checkStartCells reports whether the start cells can be applied to the board,
+// trying them on a copy of the game
+func (g *Game) checkStartCells() error {
+	trial := g.Clone()
+	if err := trial.applyStartCells(); err != nil {
+		return err
+	}
+	if trial.rule().CheckWin(trial) != nil {
+		return fmt.Errorf("%w: the pre-marked cells already decide the game", ErrInvalidHandicap)
+	}
+	return nil
+}
+
+// applyStartCells voids the free center and marks the handicap cells through the rule,
+// as if each team marked them in order at the start
+func (g *Game) applyStartCells() error {
+	size := g.Board.Size()
+	if g.FreeCenter && size%2 == 1 {
+		g.Board.Cells[size/2][size/2].Lock = CellVoid
+	}
+
+	for _, h := range g.Handicaps {
+		if !g.HasTeam(h.Team) {
+			return fmt.Errorf("%w: unknown team %q", ErrInvalidHandicap, h.Team)
+		}
+		for _, pos := range h.Cells {
+			if !g.Board.InBounds(pos[0], pos[1]) {
+				return fmt.Errorf("%w: %w", ErrInvalidHandicap, ErrInvalidPosition)
+			}
+			err := g.Board.Cells[pos[0]][pos[1]].markable()
+			if err == nil {
+				err = g.rule().Mark(g, pos[0], pos[1], Mark{Team: h.Team})
+			}
+			if err != nil {
+				return fmt.Errorf("%w: cell %d,%d for %s: %w", ErrInvalidHandicap, pos[0]+1, pos[1]+1, h.Team, err)
+			}
+		}
+	}
+	return nil
+}
+
+// fitStartCells drops start cells that no longer fit the board or the teams
+func (g *Game) fitStartCells() {
+	if g.Board.Size()%2 == 0 {
+		g.FreeCenter = false
+	}
+
+	g.Handicaps = slices.DeleteFunc(g.Handicaps, func(h Handicap) bool {
+		return !g.HasTeam(h.Team)
+	})
+	for i := range g.Handicaps {
+		g.Handicaps[i].Cells = slices.DeleteFunc(g.Handicaps[i].Cells, func(pos [2]int) bool {
+			return !g.Board.InBounds(pos[0], pos[1])
+		})
+	}
+	g.Handicaps = slices.DeleteFunc(g.Handicaps, func(h Handicap) bool {
+		return h.empty()
+	})
+}
+
+// handicap returns the team's handicap, nil if it has none
+func (g *Game) handicap(team TeamID) *Handicap {
+	for i := range g.Handicaps {
+		if g.Handicaps[i].Team == team {
+			return &g.Handicaps[i]
+		}
+	}
+	return nil
+}
+
+// TeamStartTime returns when the team can start marking, the start time plus its delay
+func (g *Game) TeamStartTime(team TeamID) time.Time {
+	if h := g.handicap(team); h != nil && !g.StartTime.IsZero() {
+		return g.StartTime.Add(h.StartDelay)
+	}
+	return g.StartTime
+}
+
+// CheckStartDelay returns an error while the team is still waiting out its start delay at now
+func (g *Game) CheckStartDelay(team TeamID, now time.Time) error {
+	if g.Status != StatusPlaying {
+		return nil
+	}
+	if start := g.TeamStartTime(team); now.Before(start) {
+		return fmt.Errorf("%w: %s left", ErrStartDelay, start.Sub(now).Round(time.Second))
+	}
+	return nil
+}
+
+// addScoreOffsets returns a copy of scores with the teams' score offsets added
+// Every rule's Scores include the offsets: mark counts in normal and blackout,
+// CalculatePhaseScore in phase and the points in points rule
+func (g *Game) addScoreOffsets(scores map[TeamID]int) map[TeamID]int {
+	result := maps.Clone(scores)
+	for _, h := range g.Handicaps {
+		if _, ok := result[h.Team]; ok {
+			result[h.Team] += h.ScoreOffset
+		}
+	}
+	return result
+}
+
+// markScores returns the mark counts with the score offsets added, for results
+// Board checks like blackout and full board use the plain CountMarks
+func (g *Game) markScores() map[TeamID]int {
+	return g.addScoreOffsets(g.CountMarks())
+}
+
+// cloneHandicaps returns a deep copy of the handicaps
+func cloneHandicaps(handicaps []Handicap) []Handicap {
+	if handicaps == nil {
+		return nil
+	}
+	c := make([]Handicap, len(handicaps))
+	for i, h := range handicaps {
+		h.Cells = slices.Clone(h.Cells)
+		c[i] = h
+	}
+	return c
+}
@@ -0,0 +1,184 @@
+package game
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFreeCenter(t *testing.T) {
+	g := NewGameWithSize(RuleNormal, 4)
+	if err := g.SetFreeCenter(true); err != ErrNoCenter {
+		t.Errorf("Free center on an even board should fail, got: %v", err)
+	}
+
+	g.SetBoardSize(3)
+	if err := g.SetFreeCenter(true); err != nil {
+		t.Fatalf("SetFreeCenter failed: %v", err)
+	}
+	g.Start()
+
+	if !g.Board.Cells[1][1].Void() {
+		t.Fatal("Center should be void once the game starts")
+	}
+	g.MarkCell(0, 1, TeamRed)
+	g.MarkCell(2, 1, TeamRed)
+	if g.Winner == nil || g.Winner.Winner != TeamRed {
+		t.Errorf("Free center should complete red's column, got: %+v", g.Winner)
+	}
+
+	// The setting survives a reset and a smaller even board drops it
+	g.Reset()
+	if g.Board.Cells[1][1].Void() || !g.FreeCenter {
+		t.Error("Reset should clear the board but keep the setting")
+	}
+	g.SetBoardSize(4)
+	if g.FreeCenter {
+		t.Error("Even board should drop the free center")
+	}
+}
+
+func TestHandicaps(t *testing.T) {
+	g := NewGameWithSize(RuleNormal, 3)
+
+	err := g.SetHandicaps([]Handicap{{Team: TeamBlue, Cells: [][2]int{{0, 0}, {1, 1}}}, {Team: TeamRed, Cells: [][2]int{{1, 1}}}})
+	if !errors.Is(err, ErrInvalidHandicap) {
+		t.Errorf("Cell given to two teams should fail, got: %v", err)
+	}
+	err = g.SetHandicaps([]Handicap{{Team: TeamBlue, Cells: [][2]int{{0, 0}, {1, 1}, {2, 2}}}})
+	if !errors.Is(err, ErrInvalidHandicap) {
+		t.Errorf("Handicap completing a line should fail, got: %v", err)
+	}
+	if err := g.SetHandicaps([]Handicap{{Team: "green", Cells: [][2]int{{0, 0}}}}); !errors.Is(err, ErrInvalidHandicap) {
+		t.Errorf("Unknown team should fail, got: %v", err)
+	}
+
+	if err := g.SetHandicaps([]Handicap{{Team: TeamBlue, Cells: [][2]int{{0, 0}, {1, 1}}}}); err != nil {
+		t.Fatalf("SetHandicaps failed: %v", err)
+	}
+	if g.Board.Cells[0][0].MarkedBy() != TeamNone {
+		t.Fatal("Handicap cells should only be marked at the start")
+	}
+
+	g.Start()
+	if g.Board.Cells[0][0].MarkedBy() != TeamBlue || g.Board.Cells[1][1].MarkedBy() != TeamBlue {
+		t.Fatalf("Handicap cells should be marked for blue, got: %v", g.CountMarks())
+	}
+	if err := g.MarkCell(0, 0, TeamRed); err != ErrCellAlreadyMarked {
+		t.Errorf("Pre-marked cell should be taken, got: %v", err)
+	}
+	g.MarkCell(2, 2, TeamBlue)
+	if g.Winner == nil || g.Winner.Winner != TeamBlue {
+		t.Errorf("Blue should win with the handicap cells, got: %+v", g.Winner)
+	}
+}
+
+func TestHandicapsPhase(t *testing.T) {
+	g := NewGameWithSize(RulePhase, 5)
+	g.PhaseConfig.UnlockThreshold = 1
+
+	// Marks go through the rule in order: the first row unlocks the second
+	if err := g.SetHandicaps([]Handicap{{Team: TeamBlue, Cells: [][2]int{{0, 0}, {1, 0}}}}); err != nil {
+		t.Fatalf("SetHandicaps failed: %v", err)
+	}
+	if err := g.SetHandicaps([]Handicap{{Team: TeamBlue, Cells: [][2]int{{2, 0}}}}); !errors.Is(err, ErrRowLocked) {
+		t.Errorf("Handicap in a locked row should fail, got: %v", err)
+	}
+
+	g.SetHandicaps([]Handicap{{Team: TeamBlue, Cells: [][2]int{{0, 0}, {1, 0}}}})
+	g.Start()
+	if state := g.TeamStates[TeamBlue]; state.RowMarks[0] != 1 || state.RowMarks[1] != 1 || state.UnlockedRow != 2 {
+		t.Errorf("Handicap should count towards phase progress, got: %+v", state)
+	}
+}
+
+func TestScoreOffsets(t *testing.T) {
+	g := NewGameWithSize(RuleNormal, 3)
+	err := g.SetHandicaps([]Handicap{{Team: TeamBlue, ScoreOffset: 3}, {Team: TeamBlue, ScoreOffset: 1}})
+	if !errors.Is(err, ErrInvalidHandicap) {
+		t.Errorf("Team listed twice should fail, got: %v", err)
+	}
+	if err := g.SetHandicaps([]Handicap{{Team: TeamBlue, ScoreOffset: MaxScoreOffset + 1}}); !errors.Is(err, ErrInvalidHandicap) {
+		t.Errorf("Offset out of range should fail, got: %v", err)
+	}
+	if err := g.SetHandicaps([]Handicap{{Team: TeamBlue, ScoreOffset: 3}, {Team: TeamRed}}); err != nil {
+		t.Fatalf("SetHandicaps failed: %v", err)
+	}
+	if len(g.Handicaps) != 1 {
+		t.Errorf("Empty handicaps should be dropped, got: %+v", g.Handicaps)
+	}
+	g.Start()
+
+	// Red takes a row, the offset only shows in the scores
+	for col := 0; col < 3; col++ {
+		g.MarkCell(0, col, TeamRed)
+	}
+	if g.Winner == nil || g.Winner.Winner != TeamRed {
+		t.Fatalf("Red should still win by bingo, got: %+v", g.Winner)
+	}
+	if g.Winner.Scores[TeamRed] != 3 || g.Winner.Scores[TeamBlue] != 3 {
+		t.Errorf("Blue's score should include the offset, got: %v", g.Winner.Scores)
+	}
+	if len(g.Winner.Handicaps) != 1 || g.Winner.Handicaps[0].ScoreOffset != 3 {
+		t.Errorf("Result should list the handicaps, got: %+v", g.Winner.Handicaps)
+	}
+	if g.CountMarks()[TeamBlue] != 0 {
+		t.Error("CountMarks should stay the plain count")
+	}
+}
+
+func TestPhaseScoreOffset(t *testing.T) {
+	g := NewGame(RulePhase)
+	g.SetHandicaps([]Handicap{{Team: TeamRed, ScoreOffset: -2}})
+	g.Start()
+
+	base := g.PhaseConfig.RowScores[0]
+	g.MarkCell(0, 0, TeamRed)
+	if scores := g.CalculatePhaseScore(); scores[TeamRed] != base-2 || scores[TeamBlue] != 0 {
+		t.Errorf("Phase score should include the offset, got: %v", scores)
+	}
+}
+
+func TestPointsScoreOffset(t *testing.T) {
+	g := NewGameWithSize(RulePoints, 3)
+	g.SetPointsTarget(3)
+	g.SetHandicaps([]Handicap{{Team: TeamBlue, ScoreOffset: 2}})
+	g.Start()
+
+	counts := g.CountPoints()
+	if scores := g.addScoreOffsets(counts); scores[TeamBlue] != 2 || counts[TeamBlue] != 0 {
+		t.Errorf("Offsets should be added to a copy, got %v from %v", scores, counts)
+	}
+
+	// Blue reaches the target with the offset and a single cell
+	g.MarkCell(0, 0, TeamBlue)
+	if g.Winner == nil || g.Winner.Winner != TeamBlue || g.Winner.Scores[TeamBlue] != 3 {
+		t.Fatalf("Blue should win with the offset, got: %+v", g.Winner)
+	}
+	if g.CountPoints()[TeamBlue] != 1 {
+		t.Error("CountPoints should stay the plain sum")
+	}
+}
+
+func TestStartDelay(t *testing.T) {
+	g := NewGameWithSize(RuleNormal, 3)
+	if err := g.SetHandicaps([]Handicap{{Team: TeamRed, StartDelay: MaxStartDelay + time.Second}}); !errors.Is(err, ErrInvalidHandicap) {
+		t.Errorf("Delay out of range should fail, got: %v", err)
+	}
+	g.SetHandicaps([]Handicap{{Team: TeamRed, StartDelay: 30 * time.Second}})
+
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	g.StartAt(start)
+	if !g.TeamStartTime(TeamRed).Equal(start.Add(30*time.Second)) || !g.TeamStartTime(TeamBlue).Equal(start) {
+		t.Errorf("Unexpected team start times: %v, %v", g.TeamStartTime(TeamRed), g.TeamStartTime(TeamBlue))
+	}
+	if err := g.CheckStartDelay(TeamRed, start.Add(10*time.Second)); !errors.Is(err, ErrStartDelay) {
+		t.Errorf("Red should still wait, got: %v", err)
+	}
+	if err := g.CheckStartDelay(TeamBlue, start); err != nil {
+		t.Errorf("Blue should mark at once, got: %v", err)
+	}
+	if err := g.CheckStartDelay(TeamRed, start.Add(30*time.Second)); err != nil {
+		t.Errorf("Red should mark once the delay is over, got: %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+package game
+
+import (
+	"errors"
+	"slices"
+)
+
+var ErrCellMarkedByTeam = errors.New("cell is already marked by your team")
+
+// SetIntent shows or hides a player's "working on it" marker on a cell
+// Intents are no marks: the rule never sees them and any mark on the cell clears them
+func (g *Game) SetIntent(row, col int, mark Mark, active bool) error {
+	if err := g.checkMark(row, col, mark.Team); err != nil {
+		return err
+	}
+
+	cell := &g.Board.Cells[row][col]
+	i := slices.IndexFunc(cell.Intents, func(m Mark) bool {
+		return m.PlayerID == mark.PlayerID
+	})
+	switch {
+	case !active:
+		if i >= 0 {
+			cell.Intents = slices.Delete(cell.Intents, i, i+1)
+		}
+	case cell.HasMark(mark.Team):
+		return ErrCellMarkedByTeam
+	case i < 0:
+		cell.Intents = append(cell.Intents, mark)
+	}
+	return nil
+}
+
+// KeepIntents copies the current intents from another state of the same game into g,
+// for states swapped in by undo and redo; intents are not undoable
+// Intents of teams that have marked the cell in g, and intents on locked cells, are dropped
+func (g *Game) KeepIntents(from *Game) {
+	if g.Board.Size() != from.Board.Size() {
+		return
+	}
+	for i, row := range g.Board.Cells {
+		for j := range row {
+			cell := &row[j]
+			cell.Intents = slices.DeleteFunc(slices.Clone(from.Board.Cells[i][j].Intents), func(m Mark) bool {
+				return cell.HasMark(m.Team) || cell.Lock != CellOpen
+			})
+		}
+	}
+}
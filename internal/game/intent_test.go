@@ -0,0 +1,65 @@
+package game
+
+import "testing"
+
+func TestIntents(t *testing.T) {
+	g := NewGameWithSize(RuleNormal, 3)
+	g.Start()
+
+	alice := Mark{Team: TeamRed, PlayerID: "u1", PlayerName: "alice"}
+	bob := Mark{Team: TeamBlue, PlayerID: "u2", PlayerName: "bob"}
+	if err := g.SetIntent(0, 0, alice, true); err != nil {
+		t.Fatalf("SetIntent failed: %v", err)
+	}
+	g.SetIntent(0, 0, alice, true)
+	g.SetIntent(0, 0, bob, true)
+
+	cell := &g.Board.Cells[0][0]
+	if len(cell.Intents) != 2 {
+		t.Fatalf("Expected one intent per player, got %+v", cell.Intents)
+	}
+	if cell.MarkedBy() != TeamNone || g.CountMarks()[TeamRed] != 0 {
+		t.Fatal("Intents should not count as marks")
+	}
+
+	g.SetIntent(0, 0, bob, false)
+	if len(cell.Intents) != 1 || cell.Intents[0].PlayerID != "u1" {
+		t.Fatalf("Turning an intent off should only drop that player, got %+v", cell.Intents)
+	}
+
+	if err := g.MarkCellBy(0, 0, alice); err != nil {
+		t.Fatalf("MarkCellBy failed: %v", err)
+	}
+	if len(cell.Intents) != 0 {
+		t.Errorf("Marking should clear intents, got %+v", cell.Intents)
+	}
+	if err := g.SetIntent(0, 0, alice, true); err != ErrCellMarkedByTeam {
+		t.Errorf("Intent on own marked cell should fail, got: %v", err)
+	}
+}
+
+func TestKeepIntents(t *testing.T) {
+	g := NewGameWithSize(RuleNormal, 3)
+	g.Start()
+	before := g.Clone()
+
+	alice := Mark{Team: TeamRed, PlayerID: "u1", PlayerName: "alice"}
+	bob := Mark{Team: TeamBlue, PlayerID: "u2", PlayerName: "bob"}
+	g.MarkCellBy(0, 0, alice)
+	g.SetIntent(1, 1, bob, true)
+
+	// An older state swapped back in keeps the intents set since
+	before.KeepIntents(g)
+	if len(before.Board.Cells[1][1].Intents) != 1 {
+		t.Errorf("Current intents should be kept, got %+v", before.Board.Cells[1][1].Intents)
+	}
+
+	// Intents on cells the team has marked in the restored state are dropped
+	g.SetIntent(2, 2, alice, true)
+	later := g.Clone()
+	later.MarkCellBy(2, 2, alice)
+	later.KeepIntents(g)
+	if len(later.Board.Cells[2][2].Intents) != 0 {
+		t.Errorf("Intent on a marked cell should be dropped, got %+v", later.Board.Cells[2][2].Intents)
+	}
+}
@@ -0,0 +1,86 @@
+package game
+
+import (
+	"encoding/json"
+)
+
+// Persisted v1 games stored teams as color numbers and kept red/blue
+// tracking in separate fields. The helpers below let those games load.
+
+// UnmarshalJSON accepts both team IDs and v1 color numbers (1 = red, 2 = blue)
+func (t *TeamID) UnmarshalJSON(data []byte) error {
+	var color int
+	if err := json.Unmarshal(data, &color); err == nil {
+		switch color {
+		case 1:
+			*t = TeamRed
+		case 2:
+			*t = TeamBlue
+		default:
+			*t = TeamNone
+		}
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*t = TeamID(s)
+	return nil
+}
+
+// UnmarshalJSON reads a cell, converting v1 marked_by/second_mark into marks
+func (c *Cell) UnmarshalJSON(data []byte) error {
+	type cellAlias Cell
+	var raw struct {
+		cellAlias
+		MarkedBy   TeamID `json:"marked_by"`
+		SecondMark TeamID `json:"second_mark"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*c = Cell(raw.cellAlias)
+	if len(c.Marks) == 0 {
+		if raw.MarkedBy != TeamNone {
+			c.Marks = append(c.Marks, Mark{Team: raw.MarkedBy})
+		}
+		if raw.SecondMark != TeamNone {
+			c.Marks = append(c.Marks, Mark{Team: raw.SecondMark})
+		}
+	}
+	return nil
+}
+
+// Normalize fills in state missing from games persisted by older versions
+// Team progress is rebuilt from the board; settlement flags cannot be recovered
+func (g *Game) Normalize() {
+	if len(g.Teams) == 0 {
+		g.Teams = DefaultTeams()
+	}
+	if g.TeamStates == nil {
+		g.TeamStates = make(map[TeamID]*TeamState, len(g.Teams))
+	}
+
+	phases := g.phases()
+	for _, t := range g.Teams {
+		if _, ok := g.TeamStates[t.ID]; ok {
+			continue
+		}
+
+		state := newTeamState(len(phases))
+		for i, phase := range phases {
+			for _, pos := range phase.Cells {
+				if g.Board.Cells[pos[0]][pos[1]].HasMark(t.ID) {
+					state.RowMarks[i]++
+				}
+			}
+		}
+		for state.UnlockedRow < len(phases)-1 && state.RowMarks[state.UnlockedRow] >= phases[state.UnlockedRow].UnlockThreshold {
+			state.UnlockedRow++
+		}
+		g.TeamStates[t.ID] = state
+	}
+}
@@ -0,0 +1,80 @@
+package game
+
+import "errors"
+
+// CellLock is a referee restriction on a cell, for goals that turn out to be broken
+type CellLock string
+
+const (
+	CellOpen   CellLock = ""       // Cell can be marked as usual
+	CellLocked CellLock = "locked" // Nobody can mark the cell, its marks still count
+	CellVoid   CellLock = "void"   // Free space for every team in line checks, its marks do not count
+)
+
+var (
+	ErrInvalidCellLock = errors.New("invalid cell lock")
+	ErrCellLockSame    = errors.New("cell already has this lock")
+	ErrCellLocked      = errors.New("cell is locked by the referee")
+	ErrCellVoid        = errors.New("cell is void")
+)
+
+// Valid reports whether the lock is a known lock state
+func (l CellLock) Valid() bool {
+	return l == CellOpen || l == CellLocked || l == CellVoid
+}
+
+// Action returns the logged action that sets the lock
+func (l CellLock) Action() EventAction {
+	switch l {
+	case CellLocked:
+		return ActionLockCell
+	case CellVoid:
+		return ActionVoidCell
+	}
+	return ActionOpenCell
+}
+
+// Void reports whether the cell counts as a free space
+func (c *Cell) Void() bool {
+	return c.Lock == CellVoid
+}
+
+// markable returns why the cell cannot be marked, nil if it can
+func (c *Cell) markable() error {
+	switch c.Lock {
+	case CellLocked:
+		return ErrCellLocked
+	case CellVoid:
+		return ErrCellVoid
+	}
+	return nil
+}
+
+// SetCellLock locks, voids or reopens a cell and checks the board again,
+// voiding a cell can complete a line and reopening one can take a win back
+// Marks on the cell are kept, so reopening it restores them
+func (g *Game) SetCellLock(row, col int, lock CellLock) error {
+	if !g.Board.InBounds(row, col) {
+		return ErrInvalidPosition
+	}
+	if !lock.Valid() {
+		return ErrInvalidCellLock
+	}
+
+	cell := &g.Board.Cells[row][col]
+	if cell.Lock == lock {
+		return ErrCellLockSame
+	}
+	cell.Lock = lock
+	if lock != CellOpen {
+		cell.Intents = nil
+	}
+
+	if !g.Started() {
+		return nil
+	}
+	// Rules recheck what they track of the cell, like the phase Bingo line, as if it was unmarked
+	g.rule().Unmarked(g, row, col, nil)
+	g.CheckWin()
+	return nil
+}
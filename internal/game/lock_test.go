@@ -0,0 +1,116 @@
+package game
+
+import "testing"
+
+func TestCellLock(t *testing.T) {
+	g := NewGameWithSize(RuleNormal, 3)
+	g.Start()
+
+	if err := g.SetCellLock(0, 0, CellLocked); err != nil {
+		t.Fatalf("SetCellLock failed: %v", err)
+	}
+	if err := g.MarkCell(0, 0, TeamRed); err != ErrCellLocked {
+		t.Errorf("Marking a locked cell should fail, got: %v", err)
+	}
+	if err := g.MarkCellForce(0, 0, TeamRed); err != ErrCellLocked {
+		t.Errorf("Force marking a locked cell should fail, got: %v", err)
+	}
+	if err := g.SetCellLock(0, 0, CellLocked); err != ErrCellLockSame {
+		t.Errorf("Setting the same lock should fail, got: %v", err)
+	}
+	if err := g.SetCellLock(0, 0, "broken"); err != ErrInvalidCellLock {
+		t.Errorf("Unknown lock should fail, got: %v", err)
+	}
+
+	g.SetCellLock(0, 0, CellOpen)
+	if err := g.MarkCell(0, 0, TeamRed); err != nil {
+		t.Errorf("Reopened cell should take marks, got: %v", err)
+	}
+}
+
+func TestVoidCellFreeSpace(t *testing.T) {
+	g := NewGameWithSize(RuleNormal, 3)
+	g.Start()
+
+	g.MarkCell(0, 0, TeamBlue)
+	g.MarkCell(0, 1, TeamRed)
+	g.MarkCell(0, 2, TeamRed)
+	if g.Status != StatusPlaying {
+		t.Fatal("Row with a blue mark should not win")
+	}
+
+	// Voiding the broken goal completes red's row, blue's mark stops counting
+	if err := g.SetCellLock(0, 0, CellVoid); err != nil {
+		t.Fatalf("SetCellLock failed: %v", err)
+	}
+	if g.Winner == nil || g.Winner.Winner != TeamRed {
+		t.Fatalf("Void cell should complete red's row, got: %+v", g.Winner)
+	}
+	if counts := g.CountMarks(); counts[TeamBlue] != 0 || counts[TeamRed] != 2 {
+		t.Errorf("Marks on void cells should not count, got: %v", counts)
+	}
+	if err := g.MarkCell(1, 1, TeamBlue); err != ErrGameFinished {
+		t.Errorf("Finished game should refuse marks, got: %v", err)
+	}
+
+	// Reopening the cell restores blue's mark and takes the win back
+	g.SetCellLock(0, 0, CellOpen)
+	if g.Status != StatusPlaying || g.Board.Cells[0][0].MarkedBy() != TeamBlue {
+		t.Errorf("Reopening should take the win back, got: %v", g.Status)
+	}
+}
+
+func TestVoidLineNeedsMark(t *testing.T) {
+	g := NewGameWithSize(RuleNormal, 3)
+	g.Start()
+
+	for col := 0; col < 3; col++ {
+		g.SetCellLock(0, col, CellVoid)
+	}
+	if g.Status != StatusPlaying || len(g.CompletedLines()) != 0 {
+		t.Errorf("Line of only void cells should count for nobody, got: %v", g.CompletedLines())
+	}
+	if err := g.MarkCell(0, 0, TeamRed); err != ErrCellVoid {
+		t.Errorf("Marking a void cell should fail, got: %v", err)
+	}
+}
+
+func TestVoidCellPhaseBingo(t *testing.T) {
+	g := NewGameWithSize(RulePhase, 3)
+	g.PhaseConfig.UnlockThreshold = 1
+	g.Start()
+
+	g.MarkCell(0, 0, TeamRed)
+	g.MarkCell(1, 0, TeamRed)
+	if g.BingoAchiever != TeamNone {
+		t.Fatal("Column is not complete yet")
+	}
+
+	// Voiding the last cell of red's column completes the phase Bingo
+	g.SetCellLock(2, 0, CellVoid)
+	if g.BingoAchiever != TeamRed || g.BingoLine != 0 {
+		t.Errorf("Void cell should complete red's column, got %q line %d", g.BingoAchiever, g.BingoLine)
+	}
+
+	g.SetCellLock(2, 0, CellOpen)
+	if g.BingoAchiever != TeamNone {
+		t.Errorf("Reopening the cell should take the Bingo back, got %q", g.BingoAchiever)
+	}
+}
+
+func TestVoidCellBlackout(t *testing.T) {
+	g := NewGameWithSize(RuleBlackout, 3)
+	g.Start()
+
+	g.SetCellLock(1, 1, CellVoid)
+	for row := 0; row < 3; row++ {
+		for col := 0; col < 3; col++ {
+			if row != 1 || col != 1 {
+				g.MarkCell(row, col, TeamRed)
+			}
+		}
+	}
+	if g.Winner == nil || g.Winner.Winner != TeamRed || g.Winner.Reason != WinReasonBlackout {
+		t.Errorf("Red should black out the board around the void cell, got: %+v", g.Winner)
+	}
+}
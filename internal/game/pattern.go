@@ -0,0 +1,195 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Win pattern names for normal rule
+const (
+	PatternLine    = "line"    // Any row, column or diagonal, LinesToWin of them
+	PatternX       = "x"       // Both diagonals
+	PatternPlus    = "plus"    // Middle row and middle column, odd boards only
+	PatternCorners = "corners" // The four corners
+	PatternFrame   = "frame"   // The outer ring of cells
+	PatternCustom  = "custom"  // Cells set in CustomPattern
+)
+
+var ErrInvalidPattern = errors.New("invalid win pattern")
+
+// PatternCells returns the cells of a named pattern on a board of the given size
+func PatternCells(name string, size int, custom [][]bool) ([][2]int, error) {
+	var cells [][2]int
+	switch name {
+	case PatternX:
+		for i := 0; i < size; i++ {
+			cells = append(cells, [2]int{i, i})
+			if i != size-1-i {
+				cells = append(cells, [2]int{i, size - 1 - i})
+			}
+		}
+	case PatternPlus:
+		if size%2 == 0 {
+			return nil, fmt.Errorf("%w: plus pattern needs a board with an odd size", ErrInvalidPattern)
+		}
+		mid := size / 2
+		for i := 0; i < size; i++ {
+			cells = append(cells, [2]int{mid, i})
+			if i != mid {
+				cells = append(cells, [2]int{i, mid})
+			}
+		}
+	case PatternCorners:
+		last := size - 1
+		cells = [][2]int{{0, 0}, {0, last}, {last, 0}, {last, last}}
+	case PatternFrame:
+		for row := 0; row < size; row++ {
+			for col := 0; col < size; col++ {
+				if row == 0 || col == 0 || row == size-1 || col == size-1 {
+					cells = append(cells, [2]int{row, col})
+				}
+			}
+		}
+	case PatternCustom:
+		if len(custom) != size {
+			return nil, fmt.Errorf("%w: custom pattern must have %d rows", ErrInvalidPattern, size)
+		}
+		for row, mask := range custom {
+			if len(mask) != size {
+				return nil, fmt.Errorf("%w: custom pattern row %d must have %d cells", ErrInvalidPattern, row+1, size)
+			}
+			for col, set := range mask {
+				if set {
+					cells = append(cells, [2]int{row, col})
+				}
+			}
+		}
+		if len(cells) == 0 {
+			return nil, fmt.Errorf("%w: custom pattern has no cells", ErrInvalidPattern)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown pattern %q", ErrInvalidPattern, name)
+	}
+	return cells, nil
+}
+
+// SetWinPatterns sets the patterns that win in normal rule (not while playing)
+// No names means lines only, the custom mask is used by the "custom" pattern
+func (g *Game) SetWinPatterns(names []string, custom [][]bool) error {
+	if g.Status == StatusPlaying || g.Status == StatusCountdown {
+		return errors.New("cannot change win patterns while playing")
+	}
+
+	size := g.Board.Size()
+	for i, name := range names {
+		if slices.Contains(names[:i], name) {
+			return fmt.Errorf("%w: duplicate pattern %q", ErrInvalidPattern, name)
+		}
+		if name == PatternLine {
+			continue
+		}
+		if _, err := PatternCells(name, size, custom); err != nil {
+			return err
+		}
+	}
+	if !slices.Contains(names, PatternCustom) {
+		custom = nil
+	}
+
+	g.WinPatterns = slices.Clone(names)
+	g.CustomPattern = cloneMask(custom)
+	return nil
+}
+
+// winPatterns returns the patterns checked in normal rule, lines if none were set
+func (g *Game) winPatterns() []string {
+	if len(g.WinPatterns) == 0 {
+		return []string{PatternLine}
+	}
+	return g.WinPatterns
+}
+
+// matchPattern reports whether the team has marked the whole pattern, returning its cells
+// The line pattern matches once the team has LinesToWin lines, with the cells of all of them
+func (g *Game) matchPattern(name string, team TeamID, lines map[TeamID][]int) ([][2]int, bool) {
+	if name == PatternLine {
+		if len(lines[team]) < max(g.LinesToWin, 1) {
+			return nil, false
+		}
+		return g.lineCells(lines[team]), true
+	}
+
+	cells, err := PatternCells(name, g.Board.Size(), g.CustomPattern)
+	if err != nil {
+		return nil, false
+	}
+	// Void cells are free spaces, but the team needs at least one mark in the pattern
+	marked := false
+	for _, pos := range cells {
+		cell := &g.Board.Cells[pos[0]][pos[1]]
+		if cell.Void() {
+			continue
+		}
+		if cell.MarkedBy() != team {
+			return nil, false
+		}
+		marked = true
+	}
+	return cells, marked
+}
+
+// lineCells returns the cells of lines given by CompletedLines indexes, each cell once
+func (g *Game) lineCells(lines []int) [][2]int {
+	size := g.Board.Size()
+	var cells [][2]int
+	add := func(row, col int) {
+		if !slices.Contains(cells, [2]int{row, col}) {
+			cells = append(cells, [2]int{row, col})
+		}
+	}
+
+	for _, line := range lines {
+		for i := 0; i < size; i++ {
+			switch {
+			case line < size:
+				add(line, i)
+			case line < 2*size:
+				add(i, line-size)
+			case line == 2*size:
+				add(i, i)
+			default:
+				add(i, size-1-i)
+			}
+		}
+	}
+	return cells
+}
+
+// fitPatterns drops the patterns that no longer fit the board,
+// like a custom mask of another size or plus on an even board
+func (g *Game) fitPatterns() {
+	size := g.Board.Size()
+	if len(g.CustomPattern) != size {
+		g.CustomPattern = nil
+	}
+	g.WinPatterns = slices.DeleteFunc(g.WinPatterns, func(name string) bool {
+		if name == PatternLine {
+			return false
+		}
+		_, err := PatternCells(name, size, g.CustomPattern)
+		return err != nil
+	})
+}
+
+// cloneMask returns a deep copy of a cell mask
+func cloneMask(mask [][]bool) [][]bool {
+	if mask == nil {
+		return nil
+	}
+	c := make([][]bool, len(mask))
+	for i, row := range mask {
+		c[i] = slices.Clone(row)
+	}
+	return c
+}
@@ -0,0 +1,109 @@
+package game
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPatternCells(t *testing.T) {
+	want := map[string]int{PatternX: 9, PatternPlus: 9, PatternCorners: 4, PatternFrame: 16}
+	for name, n := range want {
+		cells, err := PatternCells(name, 5, nil)
+		if err != nil || len(cells) != n {
+			t.Errorf("%s pattern on 5x5 should have %d cells, got %d (%v)", name, n, len(cells), err)
+		}
+	}
+	if cells, _ := PatternCells(PatternX, 4, nil); len(cells) != 8 {
+		t.Errorf("x pattern on 4x4 should have 8 cells, got %d", len(cells))
+	}
+}
+
+func TestCornersPatternWin(t *testing.T) {
+	g := NewGameWithSize(RuleNormal, 5)
+	if err := g.SetWinPatterns([]string{PatternCorners}, nil); err != nil {
+		t.Fatalf("SetWinPatterns failed: %v", err)
+	}
+	g.Start()
+
+	// A full row no longer wins
+	for col := 0; col < 5; col++ {
+		g.MarkCell(0, col, TeamRed)
+	}
+	if g.Status != StatusPlaying {
+		t.Fatal("A line should not win when only corners count")
+	}
+
+	g.MarkCell(4, 0, TeamRed)
+	g.MarkCell(4, 4, TeamRed)
+	if g.Winner == nil || g.Winner.Winner != TeamRed || g.Winner.Pattern != PatternCorners || len(g.Winner.Cells) != 4 {
+		t.Errorf("Red should win by corners, got: %+v", g.Winner)
+	}
+}
+
+func TestCustomPatternWin(t *testing.T) {
+	g := NewGameWithSize(RuleNormal, 3)
+	mask := [][]bool{
+		{false, true, false},
+		{true, false, true},
+		{false, true, false},
+	}
+	if err := g.SetWinPatterns([]string{PatternLine, PatternCustom}, mask); err != nil {
+		t.Fatalf("SetWinPatterns failed: %v", err)
+	}
+	mask[0][0] = true // The game keeps its own copy
+	g.Start()
+
+	for _, pos := range [][2]int{{0, 1}, {1, 0}, {1, 2}, {2, 1}} {
+		g.MarkCell(pos[0], pos[1], TeamBlue)
+	}
+	if g.Winner == nil || g.Winner.Winner != TeamBlue || g.Winner.Pattern != PatternCustom {
+		t.Errorf("Blue should win by the custom pattern, got: %+v", g.Winner)
+	}
+}
+
+func TestLinePatternCells(t *testing.T) {
+	g := NewGameWithSize(RuleNormal, 3)
+	g.Start()
+	for i := 0; i < 3; i++ {
+		g.MarkCell(i, 2-i, TeamRed)
+	}
+	if g.Winner == nil || g.Winner.Pattern != PatternLine || len(g.Winner.Cells) != 3 || g.Winner.Cells[0] != [2]int{0, 2} {
+		t.Errorf("Red should win by the / diagonal, got: %+v", g.Winner)
+	}
+}
+
+func TestSetWinPatternsValidation(t *testing.T) {
+	g := NewGameWithSize(RuleNormal, 3)
+	if err := g.SetWinPatterns([]string{"star"}, nil); !errors.Is(err, ErrInvalidPattern) {
+		t.Errorf("Unknown pattern should fail, got: %v", err)
+	}
+	if err := g.SetWinPatterns([]string{PatternCustom}, [][]bool{{true}}); !errors.Is(err, ErrInvalidPattern) {
+		t.Errorf("Custom mask of the wrong size should fail, got: %v", err)
+	}
+	if err := g.SetWinPatterns([]string{PatternX, PatternX}, nil); !errors.Is(err, ErrInvalidPattern) {
+		t.Errorf("Duplicate pattern should fail, got: %v", err)
+	}
+
+	mask := [][]bool{{true, false, false}, {false, false, false}, {false, false, true}}
+	g.SetWinPatterns([]string{PatternX, PatternCustom}, mask)
+	g.SetBoardSize(5)
+	if len(g.WinPatterns) != 1 || g.WinPatterns[0] != PatternX || g.CustomPattern != nil {
+		t.Errorf("Resizing should drop the custom pattern, got: %v %v", g.WinPatterns, g.CustomPattern)
+	}
+}
+
+func TestPlusPatternEvenBoard(t *testing.T) {
+	g := NewGameWithSize(RuleNormal, 4)
+	if err := g.SetWinPatterns([]string{PatternPlus}, nil); !errors.Is(err, ErrInvalidPattern) {
+		t.Errorf("Plus on an even board should fail, got: %v", err)
+	}
+
+	g.SetBoardSize(5)
+	if err := g.SetWinPatterns([]string{PatternLine, PatternPlus}, nil); err != nil {
+		t.Fatalf("SetWinPatterns failed: %v", err)
+	}
+	g.SetBoardSize(4)
+	if len(g.WinPatterns) != 1 || g.WinPatterns[0] != PatternLine {
+		t.Errorf("Even board should drop the plus pattern, got: %v", g.WinPatterns)
+	}
+}
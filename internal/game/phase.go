@@ -0,0 +1,225 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrInvalidPhaseConfig = errors.New("invalid phase config")
+	ErrInvalidPhases      = errors.New("invalid phase layout")
+	ErrCellNotInPhase     = errors.New("cell is not part of any phase")
+)
+
+// SettleMarks is how many marks in the last phase let a team settle first,
+// fewer if the phase does not allow that many
+const SettleMarks = 2
+
+// Phase config error codes
+const (
+	ConfigErrLength      = "length"        // Per-row list does not have one value per row
+	ConfigErrNegative    = "negative"      // Value must be 0 or more
+	ConfigErrTooSmall    = "too_small"     // Value must be at least 1
+	ConfigErrUnreachable = "unreachable"   // Threshold is more than a team can ever mark
+	ConfigErrEmpty       = "empty"         // Phase has no cells
+	ConfigErrOutOfBounds = "out_of_bounds" // Phase cell is off the board
+	ConfigErrOverlap     = "overlap"       // Cell is in more than one phase
+)
+
+// ConfigError is a rejected phase config field
+type ConfigError struct {
+	Field   string // JSON path of the field, e.g. "cells_per_row" or "phases[2].limit"
+	Code    string // One of the ConfigErr* codes
+	Message string
+	Err     error // ErrInvalidPhaseConfig, or ErrInvalidPhases for the phases field
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("%v: %s %s", e.Err, e.Field, e.Message)
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// Validate checks a phase config for a board of the given size
+// It returns a *ConfigError for the first field that is invalid or can never be reached,
+// minimums come from the phase rule's ConfigSchema
+func (c PhaseConfig) Validate(size int) error {
+	invalid := func(field, code, format string, args ...any) error {
+		return &ConfigError{Field: field, Code: code, Message: fmt.Sprintf(format, args...), Err: ErrInvalidPhaseConfig}
+	}
+
+	schema := phaseRule{}.ConfigSchema()
+	ints := map[string]int{
+		"cells_per_row":    c.CellsPerRow,
+		"unlock_threshold": c.UnlockThreshold,
+		"bingo_bonus":      c.BingoBonus,
+		"final_bonus":      c.FinalBonus,
+	}
+	// Single values are checked even when phases replace the per-row fields
+	for _, f := range schema {
+		if v, ok := ints[f.Key]; ok {
+			if err := f.checkMin(f.Key, v); err != nil {
+				return err
+			}
+		}
+	}
+	if len(c.Phases) > 0 {
+		return validatePhases(c.Phases, size)
+	}
+
+	perRow := map[string][]int{"row_scores": c.RowScores, "second_half_scores": c.SecondHalfScores}
+	for _, f := range schema {
+		values, ok := perRow[f.Key]
+		if !ok {
+			continue
+		}
+		if len(values) != size {
+			return invalid(f.Key, ConfigErrLength, "must have %d values, one per row, got %d", size, len(values))
+		}
+		for row, v := range values {
+			if err := f.checkMin(fmt.Sprintf("%s[%d]", f.Key, row), v); err != nil {
+				return err
+			}
+		}
+	}
+	if reachable := min(c.CellsPerRow, size); c.UnlockThreshold > reachable {
+		return invalid("unlock_threshold", ConfigErrUnreachable, "is %d but a team can mark only %d cells per row", c.UnlockThreshold, reachable)
+	}
+	return nil
+}
+
+// RowPhases returns one phase per row, built from the per-row fields of the config
+func RowPhases(config PhaseConfig, size int) []Phase {
+	phases := make([]Phase, size)
+	for row := range phases {
+		cells := make([][2]int, size)
+		for col := range cells {
+			cells[col] = [2]int{row, col}
+		}
+		phases[row] = Phase{
+			Cells:           cells,
+			Score:           valueAt(config.RowScores, row),
+			LaterScore:      valueAt(config.SecondHalfScores, row),
+			Limit:           config.CellsPerRow,
+			UnlockThreshold: config.UnlockThreshold,
+		}
+	}
+	return phases
+}
+
+// valueAt returns values[i], or 0 if values is too short
+func valueAt(values []int, i int) int {
+	if i < len(values) {
+		return values[i]
+	}
+	return 0
+}
+
+// validatePhases checks that custom phases fit the board, share no cells and can be unlocked
+func validatePhases(phases []Phase, size int) error {
+	invalid := func(i int, field, code, format string, args ...any) error {
+		return &ConfigError{Field: fmt.Sprintf("phases[%d]%s", i, field), Code: code, Message: fmt.Sprintf(format, args...), Err: ErrInvalidPhases}
+	}
+
+	seen := make(map[[2]int]int)
+	for i, p := range phases {
+		if len(p.Cells) == 0 {
+			return invalid(i, ".cells", ConfigErrEmpty, "must have at least 1 cell")
+		}
+		for _, pos := range p.Cells {
+			if pos[0] < 0 || pos[0] >= size || pos[1] < 0 || pos[1] >= size {
+				return invalid(i, ".cells", ConfigErrOutOfBounds, "has cell %v outside the %dx%d board", pos, size, size)
+			}
+			if other, ok := seen[pos]; ok {
+				return invalid(i, ".cells", ConfigErrOverlap, "has cell %v, which is already in phase %d", pos, other+1)
+			}
+			seen[pos] = i
+		}
+		if p.Score < 0 {
+			return invalid(i, ".score", ConfigErrNegative, "must not be negative, got %d", p.Score)
+		}
+		if p.LaterScore < 0 {
+			return invalid(i, ".later_score", ConfigErrNegative, "must not be negative, got %d", p.LaterScore)
+		}
+		if p.Limit < 1 {
+			return invalid(i, ".limit", ConfigErrTooSmall, "must be at least 1, got %d", p.Limit)
+		}
+		if p.UnlockThreshold < 1 {
+			return invalid(i, ".unlock_threshold", ConfigErrTooSmall, "must be at least 1, got %d", p.UnlockThreshold)
+		}
+		// The last phase unlocks nothing, its threshold is never checked
+		if reachable := min(p.Limit, len(p.Cells)); i < len(phases)-1 && p.UnlockThreshold > reachable {
+			return invalid(i, ".unlock_threshold", ConfigErrUnreachable, "is %d but a team can mark only %d cells in phase %d", p.UnlockThreshold, reachable, i+1)
+		}
+	}
+	return nil
+}
+
+// phases returns the game's phases: the configured cell groups, or one per row
+func (g *Game) phases() []Phase {
+	if len(g.PhaseConfig.Phases) > 0 {
+		return g.PhaseConfig.Phases
+	}
+	return RowPhases(g.PhaseConfig, g.Board.Size())
+}
+
+// phaseCount returns the number of phases
+func (g *Game) phaseCount() int {
+	if len(g.PhaseConfig.Phases) > 0 {
+		return len(g.PhaseConfig.Phases)
+	}
+	return g.Board.Size()
+}
+
+// phaseOf returns the index of the phase holding a cell, or -1
+func (g *Game) phaseOf(row, col int) int {
+	if len(g.PhaseConfig.Phases) == 0 {
+		return row
+	}
+	for i, p := range g.PhaseConfig.Phases {
+		for _, pos := range p.Cells {
+			if pos == [2]int{row, col} {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// lastPhase returns the index of the last phase
+func (g *Game) lastPhase() int {
+	return g.phaseCount() - 1
+}
+
+// phaseName names a phase in messages, "row 5" unless custom phases are set
+func (g *Game) phaseName(phase int) string {
+	if len(g.PhaseConfig.Phases) == 0 {
+		return fmt.Sprintf("row %d", phase+1)
+	}
+	return fmt.Sprintf("phase %d", phase+1)
+}
+
+// settleMarks returns how many marks in the last phase a team needs to settle first
+func (g *Game) settleMarks() int {
+	last := g.phases()[g.lastPhase()]
+	return min(SettleMarks, last.Limit, len(last.Cells))
+}
+
+// settleError reports the marks missing to settle, built from the phase config
+type settleError struct {
+	need  int
+	phase string
+}
+
+func (e *settleError) Error() string {
+	if e.need == 1 {
+		return fmt.Sprintf("need at least 1 cell in %s to settle", e.phase)
+	}
+	return fmt.Sprintf("need at least %d cells in %s to settle", e.need, e.phase)
+}
+
+func (e *settleError) Is(target error) bool {
+	return target == ErrCannotSettleYet
+}
@@ -0,0 +1,194 @@
+package game
+
+import (
+	"errors"
+	"testing"
+)
+
+// ringPhases splits a 5x5 board into three phases: the center and corners,
+// the ring around the center, and the rest of the outer ring
+func ringPhases() []Phase {
+	phases := []Phase{
+		{Score: 1, LaterScore: 0, Limit: 2, UnlockThreshold: 1},
+		{Score: 3, LaterScore: 1, Limit: 3, UnlockThreshold: 2},
+		{Score: 5, LaterScore: 2, Limit: 4, UnlockThreshold: 1},
+	}
+	for row := 0; row < 5; row++ {
+		for col := 0; col < 5; col++ {
+			corner := (row == 0 || row == 4) && (col == 0 || col == 4)
+			switch {
+			case corner || (row == 2 && col == 2):
+				phases[0].Cells = append(phases[0].Cells, [2]int{row, col})
+			case row >= 1 && row <= 3 && col >= 1 && col <= 3:
+				phases[1].Cells = append(phases[1].Cells, [2]int{row, col})
+			default:
+				phases[2].Cells = append(phases[2].Cells, [2]int{row, col})
+			}
+		}
+	}
+	return phases
+}
+
+func TestCustomPhases(t *testing.T) {
+	g := NewGame(RulePhase)
+	config := DefaultPhaseConfig(5)
+	config.Phases = ringPhases()
+	if err := g.SetRule(RulePhase, config); err != nil {
+		t.Fatalf("SetRule failed: %v", err)
+	}
+	if len(g.TeamStates[TeamRed].RowMarks) != 3 {
+		t.Fatalf("Team state should track 3 phases, got %d", len(g.TeamStates[TeamRed].RowMarks))
+	}
+	g.Start()
+
+	if err := g.MarkCell(1, 1, TeamRed); !errors.Is(err, ErrRowLocked) {
+		t.Errorf("Second phase should be locked, got: %v", err)
+	}
+
+	// One mark of the first phase unlocks the second
+	g.MarkCell(2, 2, TeamRed)
+	if err := g.MarkCell(1, 1, TeamRed); err != nil {
+		t.Fatalf("Second phase should be unlocked: %v", err)
+	}
+	g.MarkCell(1, 2, TeamRed)
+	g.MarkCell(1, 3, TeamRed)
+	if err := g.MarkCell(2, 1, TeamRed); !errors.Is(err, ErrRowLimitExceeded) {
+		t.Errorf("Second phase allows 3 marks, got: %v", err)
+	}
+	if g.TeamStates[TeamRed].UnlockedRow != 2 {
+		t.Fatalf("Third phase should be unlocked, got %d", g.TeamStates[TeamRed].UnlockedRow)
+	}
+
+	g.MarkCell(0, 1, TeamRed)
+	g.MarkCell(0, 2, TeamRed)
+	g.MarkCell(0, 0, TeamBlue)
+	if !g.CanSettle(TeamRed) || g.CanSettle(TeamBlue) {
+		t.Error("Only red has two marks in the last phase")
+	}
+
+	scores := g.CalculatePhaseScore()
+	if scores[TeamRed] != 1+3*3+5*2 || scores[TeamBlue] != 1 {
+		t.Errorf("Unexpected phase scores: %v", scores)
+	}
+
+	// Dropping below the second phase's threshold locks the third phase again
+	g.ClearCellMark(1, 2, TeamRed)
+	g.UnmarkCell(1, 3)
+	if g.TeamStates[TeamRed].UnlockedRow != 1 || g.TeamStates[TeamRed].RowMarks[1] != 1 {
+		t.Errorf("Third phase should lock again, got state %+v", g.TeamStates[TeamRed])
+	}
+}
+
+func TestCustomPhasesValidation(t *testing.T) {
+	g := NewGame(RulePhase)
+	config := DefaultPhaseConfig(5)
+
+	config.Phases = ringPhases()
+	config.Phases[1].Cells = append(config.Phases[1].Cells, [2]int{2, 2})
+	if err := g.SetRule(RulePhase, config); !errors.Is(err, ErrInvalidPhases) {
+		t.Errorf("Cell in two phases should fail, got: %v", err)
+	}
+
+	config.Phases = []Phase{{Cells: [][2]int{{5, 0}}, Limit: 1, UnlockThreshold: 1}}
+	if err := g.SetRule(RulePhase, config); !errors.Is(err, ErrInvalidPhases) {
+		t.Errorf("Cell off the board should fail, got: %v", err)
+	}
+
+	// Cells outside every phase cannot be marked
+	config.Phases = []Phase{{Cells: [][2]int{{0, 0}}, Score: 1, Limit: 1, UnlockThreshold: 1}}
+	if err := g.SetRule(RulePhase, config); err != nil {
+		t.Fatalf("SetRule failed: %v", err)
+	}
+	g.Start()
+	if err := g.MarkCell(1, 1, TeamRed); !errors.Is(err, ErrCellNotInPhase) {
+		t.Errorf("Cell outside the phases should not be markable, got: %v", err)
+	}
+}
+
+func TestPhaseConfigValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		edit  func(c *PhaseConfig)
+		field string
+		code  string
+	}{
+		{"short row scores", func(c *PhaseConfig) { c.RowScores = []int{1, 2} }, "row_scores", ConfigErrLength},
+		{"negative later score", func(c *PhaseConfig) { c.SecondHalfScores[3] = -1 }, "second_half_scores[3]", ConfigErrNegative},
+		{"no cells per row", func(c *PhaseConfig) { c.CellsPerRow = -2 }, "cells_per_row", ConfigErrTooSmall},
+		{"no unlock threshold", func(c *PhaseConfig) { c.UnlockThreshold = 0 }, "unlock_threshold", ConfigErrTooSmall},
+		{"threshold over limit", func(c *PhaseConfig) { c.UnlockThreshold = 4 }, "unlock_threshold", ConfigErrUnreachable},
+		{"negative bonus", func(c *PhaseConfig) { c.FinalBonus = -3 }, "final_bonus", ConfigErrNegative},
+		{"phase threshold over cells", func(c *PhaseConfig) {
+			c.Phases = ringPhases()
+			c.Phases[0].Limit = 5
+			c.Phases[0].UnlockThreshold = 6
+		}, "phases[0].unlock_threshold", ConfigErrUnreachable},
+		{"phase without limit", func(c *PhaseConfig) {
+			c.Phases = ringPhases()
+			c.Phases[2].Limit = 0
+		}, "phases[2].limit", ConfigErrTooSmall},
+	}
+
+	for _, tt := range tests {
+		config := DefaultPhaseConfig(5)
+		tt.edit(&config)
+		var configErr *ConfigError
+		if err := config.Validate(5); !errors.As(err, &configErr) {
+			t.Errorf("%s: expected a config error, got: %v", tt.name, err)
+			continue
+		}
+		if configErr.Field != tt.field || configErr.Code != tt.code {
+			t.Errorf("%s: expected %s/%s, got %s/%s", tt.name, tt.field, tt.code, configErr.Field, configErr.Code)
+		}
+	}
+
+	for size := MinBoardSize; size <= MaxBoardSize; size++ {
+		if err := DefaultPhaseConfig(size).Validate(size); err != nil {
+			t.Errorf("Default config for size %d should be valid: %v", size, err)
+		}
+	}
+
+	// The last phase unlocks nothing, so its threshold may be out of reach
+	config := DefaultPhaseConfig(5)
+	config.Phases = ringPhases()
+	config.Phases[2].UnlockThreshold = 10
+	if err := config.Validate(5); err != nil {
+		t.Errorf("Threshold of the last phase should not matter: %v", err)
+	}
+
+	g := NewGame(RulePhase)
+	config = DefaultPhaseConfig(5)
+	config.UnlockThreshold = 4
+	if err := g.SetRule(RulePhase, config); !errors.Is(err, ErrInvalidPhaseConfig) {
+		t.Errorf("SetRule should reject the config, got: %v", err)
+	}
+}
+
+func TestSettleRequirement(t *testing.T) {
+	g := NewGame(RulePhase)
+	g.Start()
+	err := g.Settle(TeamRed)
+	if !errors.Is(err, ErrCannotSettleYet) || err.Error() != "need at least 2 cells in row 5 to settle" {
+		t.Errorf("Unexpected settle error: %v", err)
+	}
+
+	// A last phase with one cell needs just that cell
+	config := DefaultPhaseConfig(5)
+	config.Phases = []Phase{
+		{Cells: [][2]int{{0, 0}, {0, 1}}, Score: 1, Limit: 2, UnlockThreshold: 1},
+		{Cells: [][2]int{{4, 4}}, Score: 5, Limit: 1, UnlockThreshold: 1},
+	}
+	g = NewGame(RulePhase)
+	if err := g.SetRule(RulePhase, config); err != nil {
+		t.Fatalf("SetRule failed: %v", err)
+	}
+	g.Start()
+	if err := g.Settle(TeamRed); err == nil || err.Error() != "need at least 1 cell in phase 2 to settle" {
+		t.Errorf("Unexpected settle error: %v", err)
+	}
+	g.MarkCell(0, 0, TeamRed)
+	g.MarkCell(4, 4, TeamRed)
+	if err := g.Settle(TeamRed); err != nil {
+		t.Errorf("Red should settle with the only cell of the last phase: %v", err)
+	}
+}
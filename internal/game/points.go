@@ -0,0 +1,113 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MaxCellPoints is the highest point value a cell can have
+const MaxCellPoints = 1000
+
+var (
+	ErrInvalidPoints       = fmt.Errorf("cell points must be between 0 and %d", MaxCellPoints)
+	ErrInvalidPointsTarget = errors.New("points target cannot be negative")
+)
+
+// Value returns the cell's point value, 1 if none was set
+func (c *Cell) Value() int {
+	if c.Points <= 0 {
+		return 1
+	}
+	return c.Points
+}
+
+// SetCellPoints sets the point value of a cell, 0 restores the default of 1
+func (g *Game) SetCellPoints(row, col, points int) error {
+	if !g.Board.InBounds(row, col) {
+		return ErrInvalidPosition
+	}
+	if points < 0 || points > MaxCellPoints {
+		return ErrInvalidPoints
+	}
+
+	g.Board.Cells[row][col].Points = points
+	return nil
+}
+
+// SetAllCellPoints sets all cell point values at once, in row-major order
+func (g *Game) SetAllCellPoints(points []int) error {
+	size := g.Board.Size()
+	if len(points) != size*size {
+		return fmt.Errorf("must provide exactly %d point values", size*size)
+	}
+	for _, p := range points {
+		if p < 0 || p > MaxCellPoints {
+			return ErrInvalidPoints
+		}
+	}
+
+	for row := 0; row < size; row++ {
+		for col := 0; col < size; col++ {
+			g.Board.Cells[row][col].Points = points[row*size+col]
+		}
+	}
+	return nil
+}
+
+// SetPointsTarget sets the score that wins in points rule, 0 plays until the board
+// is full or the time limit runs out (not while playing)
+func (g *Game) SetPointsTarget(target int) error {
+	if g.Status == StatusPlaying || g.Status == StatusCountdown {
+		return errors.New("cannot change points target while playing")
+	}
+	if target < 0 {
+		return ErrInvalidPointsTarget
+	}
+	g.PointsTarget = target
+	return nil
+}
+
+// CountPoints sums the point values of the cells each team marked first, void cells are worth nothing
+func (g *Game) CountPoints() map[TeamID]int {
+	scores := g.newScores()
+	for _, row := range g.Board.Cells {
+		for _, cell := range row {
+			if team := cell.MarkedBy(); team != TeamNone && !cell.Void() {
+				scores[team] += cell.Value()
+			}
+		}
+	}
+	return scores
+}
+
+// checkPointsWin checks for winner in points rule: the first team to reach the
+// target, or the team with the most points once every cell is taken
+func (g *Game) checkPointsWin() *Winner {
+	scores := g.addScoreOffsets(g.CountPoints())
+
+	if g.PointsTarget > 0 {
+		for _, t := range g.Teams {
+			if scores[t.ID] >= g.PointsTarget {
+				return &Winner{
+					Winner: t.ID,
+					Reason: WinReasonPoints,
+					Scores: scores,
+				}
+			}
+		}
+	}
+
+	for _, row := range g.Board.Cells {
+		for _, cell := range row {
+			if len(cell.Marks) == 0 && !cell.Void() {
+				return nil
+			}
+		}
+	}
+
+	return &Winner{
+		Winner: g.leader(scores),
+		Reason: WinReasonFullBoard,
+		Scores: scores,
+	}
+}
@@ -0,0 +1,71 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPointsRuleTarget(t *testing.T) {
+	g := NewGameWithSize(RulePoints, 3)
+	g.SetCellPoints(0, 0, 5)
+	g.SetCellPoints(1, 1, 3)
+	if err := g.SetPointsTarget(6); err != nil {
+		t.Fatalf("SetPointsTarget failed: %v", err)
+	}
+	g.Start()
+
+	g.MarkCell(0, 0, TeamRed)
+	if err := g.MarkCell(0, 0, TeamBlue); err != ErrCellAlreadyMarked {
+		t.Errorf("Cells should only be taken once, got: %v", err)
+	}
+
+	// Cells without a value are worth 1
+	g.MarkCell(1, 1, TeamBlue)
+	if g.Status != StatusPlaying {
+		t.Fatalf("Nobody has reached the target yet, got status %v", g.Status)
+	}
+	g.MarkCell(0, 1, TeamRed)
+
+	if g.Winner == nil || g.Winner.Winner != TeamRed || g.Winner.Reason != WinReasonPoints {
+		t.Fatalf("Red should win by points, got: %+v", g.Winner)
+	}
+	if g.Winner.Scores[TeamRed] != 6 || g.Winner.Scores[TeamBlue] != 3 {
+		t.Errorf("Unexpected scores: %v", g.Winner.Scores)
+	}
+}
+
+func TestPointsRuleTimeout(t *testing.T) {
+	g := NewGameWithSize(RulePoints, 3)
+	g.SetAllCellPoints([]int{1, 1, 1, 1, 10, 1, 1, 1, 1})
+	g.SetTimeLimit(time.Minute)
+	g.StartAt(clockStart)
+
+	// A full row is no win in points rule
+	g.MarkCell(0, 0, TeamRed)
+	g.MarkCell(0, 1, TeamRed)
+	g.MarkCell(0, 2, TeamRed)
+	g.MarkCell(1, 1, TeamBlue)
+	if g.Status != StatusPlaying {
+		t.Fatalf("Game without a target should run to the limit, got status %v", g.Status)
+	}
+
+	if !g.UpdateClock(clockStart.Add(time.Minute)) {
+		t.Fatal("Game should time out")
+	}
+	if g.Winner.Winner != TeamBlue || g.Winner.Reason != WinReasonTimeout {
+		t.Errorf("Blue should win on points at the time limit, got: %+v", g.Winner)
+	}
+}
+
+func TestSetCellPointsValidation(t *testing.T) {
+	g := NewGameWithSize(RulePoints, 3)
+	if err := g.SetCellPoints(0, 0, MaxCellPoints+1); err != ErrInvalidPoints {
+		t.Errorf("Too many points should fail, got: %v", err)
+	}
+	if err := g.SetAllCellPoints([]int{1, 2}); err == nil {
+		t.Error("Point values for part of the board should fail")
+	}
+	if err := g.SetPointsTarget(-1); err != ErrInvalidPointsTarget {
+		t.Errorf("Negative target should fail, got: %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MaxCellTarget is the highest target count a cell can have
+const MaxCellTarget = 1000
+
+var (
+	ErrInvalidTarget = fmt.Errorf("cell target must be between 0 and %d", MaxCellTarget)
+	ErrNoTarget      = errors.New("cell has no target count")
+	ErrProgressLimit = errors.New("counter is already at 0 or at the target")
+)
+
+// SetCellTarget sets the count a team has to reach on a cell, 0 removes it
+func (g *Game) SetCellTarget(row, col, target int) error {
+	if !g.Board.InBounds(row, col) {
+		return ErrInvalidPosition
+	}
+	if target < 0 || target > MaxCellTarget {
+		return ErrInvalidTarget
+	}
+
+	cell := &g.Board.Cells[row][col]
+	cell.Target = target
+	cell.Progress = nil
+	return nil
+}
+
+// SetAllCellTargets sets all cell targets at once, in row-major order
+func (g *Game) SetAllCellTargets(targets []int) error {
+	size := g.Board.Size()
+	if len(targets) != size*size {
+		return fmt.Errorf("must provide exactly %d targets", size*size)
+	}
+	for _, t := range targets {
+		if t < 0 || t > MaxCellTarget {
+			return ErrInvalidTarget
+		}
+	}
+
+	for row := 0; row < size; row++ {
+		for col := 0; col < size; col++ {
+			g.SetCellTarget(row, col, targets[row*size+col])
+		}
+	}
+	return nil
+}
+
+// AddProgress raises or lowers a team's counter on a cell with a target, within 0 and the target
+// Reaching the target marks the cell for the team (or claims it with claim verification),
+// dropping below it again takes back that mark or claim, but not one made by hand
+// If the rule refuses the mark, like on a cell another team has taken, the error is returned
+// and the counter stays below the target, so counting up tries again
+func (g *Game) AddProgress(row, col int, mark Mark, delta int) error {
+	if err := g.checkMark(row, col, mark.Team); err != nil {
+		return err
+	}
+
+	cell := &g.Board.Cells[row][col]
+	if cell.Target == 0 {
+		return ErrNoTarget
+	}
+
+	before := cell.Progress[mark.Team]
+	after := min(max(before+delta, 0), cell.Target)
+	if after == before {
+		return ErrProgressLimit
+	}
+
+	switch {
+	case after == cell.Target && (cell.HasMark(mark.Team) || cell.claimIndex(mark.Team) >= 0):
+		// Already marked or claimed by hand
+	case after == cell.Target:
+		mark.Counted = true
+		var err error
+		if g.VerifyClaims {
+			err = g.ClaimCell(row, col, mark)
+		} else {
+			err = g.MarkCellBy(row, col, mark)
+		}
+		if err != nil {
+			return err
+		}
+	case before == cell.Target:
+		if i := cell.claimIndex(mark.Team); i >= 0 && cell.Claims[i].Counted {
+			g.RejectClaim(row, col, mark.Team)
+		} else if i := cell.markIndex(mark.Team); i >= 0 && cell.Marks[i].Counted {
+			if err := g.ClearCellMark(row, col, mark.Team); err != nil {
+				return err
+			}
+		}
+	}
+
+	if cell.Progress == nil {
+		cell.Progress = make(map[TeamID]int)
+	}
+	cell.Progress[mark.Team] = after
+	if after == 0 {
+		delete(cell.Progress, mark.Team)
+	}
+	return nil
+}
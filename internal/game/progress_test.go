@@ -0,0 +1,123 @@
+package game
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestProgressAutoMark(t *testing.T) {
+	g := NewGameWithSize(RuleNormal, 3)
+	g.SetCellTarget(0, 0, 3)
+	g.Start()
+
+	red := Mark{Team: TeamRed, PlayerName: "alice"}
+	if err := g.AddProgress(1, 1, red, 1); err != ErrNoTarget {
+		t.Errorf("Cell without a target should have no counter, got: %v", err)
+	}
+	if err := g.AddProgress(0, 0, red, -1); err != ErrProgressLimit {
+		t.Errorf("Counter should not go below 0, got: %v", err)
+	}
+
+	g.AddProgress(0, 0, red, 1)
+	g.AddProgress(0, 0, red, 1)
+	g.AddProgress(0, 0, Mark{Team: TeamBlue}, 1)
+	if g.Board.Cells[0][0].MarkedBy() != TeamNone {
+		t.Fatal("Cell should not be marked below the target")
+	}
+	if p := g.Board.Cells[0][0].Progress; p[TeamRed] != 2 || p[TeamBlue] != 1 {
+		t.Errorf("Unexpected counters: %v", p)
+	}
+
+	g.AddProgress(0, 0, red, 1)
+	if cell := g.Board.Cells[0][0]; cell.MarkedBy() != TeamRed || cell.Marks[0].PlayerName != "alice" {
+		t.Errorf("Reaching the target should mark the cell, got %+v", cell)
+	}
+	if err := g.AddProgress(0, 0, red, 5); err != ErrProgressLimit {
+		t.Errorf("Counter should stop at the target, got: %v", err)
+	}
+
+	// Blue cannot take a cell red already has in normal rule, the refusal is returned
+	// and its counter stays below the target
+	g.AddProgress(0, 0, Mark{Team: TeamBlue}, 1)
+	if err := g.AddProgress(0, 0, Mark{Team: TeamBlue}, 1); err != ErrCellAlreadyMarked {
+		t.Errorf("Counting up to the target on a taken cell should be refused, got: %v", err)
+	}
+	if cell := g.Board.Cells[0][0]; cell.Progress[TeamBlue] != 2 || cell.MarkedBy() != TeamRed || len(cell.Marks) != 1 {
+		t.Errorf("Refused mark should keep the counter below the target and leave the cell to red, got %+v", cell)
+	}
+
+	// Counting back down takes the mark back
+	g.AddProgress(0, 0, red, -1)
+	if g.Board.Cells[0][0].MarkedBy() != TeamNone {
+		t.Error("Dropping below the target should remove the mark")
+	}
+
+	// Once the cell is free, counting up again marks it for blue
+	if err := g.AddProgress(0, 0, Mark{Team: TeamBlue}, 1); err != nil || g.Board.Cells[0][0].MarkedBy() != TeamBlue {
+		t.Errorf("Retrying the count should mark the cell, got: %v", err)
+	}
+}
+
+func TestProgressKeepsHandMarks(t *testing.T) {
+	g := NewGameWithSize(RuleNormal, 3)
+	g.SetCellTarget(0, 0, 2)
+	g.Start()
+
+	red := Mark{Team: TeamRed}
+	g.AddProgress(0, 0, red, 1)
+	g.MarkCell(0, 0, TeamRed)
+	g.AddProgress(0, 0, red, 1)
+	g.AddProgress(0, 0, red, -1)
+	if g.Board.Cells[0][0].MarkedBy() != TeamRed {
+		t.Error("Counting down should not remove a mark made by hand")
+	}
+}
+
+func TestProgressClaims(t *testing.T) {
+	g := NewGameWithSize(RuleNormal, 3)
+	g.SetVerifyClaims(true)
+	g.SetCellTarget(1, 1, 1)
+	g.Start()
+
+	g.AddProgress(1, 1, Mark{Team: TeamRed}, 1)
+	cell := &g.Board.Cells[1][1]
+	if cell.MarkedBy() != TeamNone || len(cell.Claims) != 1 {
+		t.Fatalf("Reaching the target should claim the cell, got %+v", cell)
+	}
+	g.AddProgress(1, 1, Mark{Team: TeamRed}, -1)
+	if len(cell.Claims) != 0 {
+		t.Error("Dropping below the target should withdraw the claim")
+	}
+}
+
+func TestCellTargetValidation(t *testing.T) {
+	g := NewGameWithSize(RuleNormal, 3)
+	if err := g.SetCellTarget(0, 0, MaxCellTarget+1); err != ErrInvalidTarget {
+		t.Errorf("Too high a target should fail, got: %v", err)
+	}
+	if err := g.SetAllCellTargets([]int{1, 2}); err == nil {
+		t.Error("Targets for part of the board should fail")
+	}
+	if err := ValidateGoalPool([]Goal{{Text: "a", Difficulty: 1, Target: -1}}); !errors.Is(err, ErrInvalidTarget) {
+		t.Errorf("Goal with a negative target should fail, got: %v", err)
+	}
+
+	goals := rankedPool(9)
+	goals[0].Target = 5
+	if err := g.GenerateBoard(goals, 1); err != nil {
+		t.Fatalf("GenerateBoard failed: %v", err)
+	}
+	found := false
+	for _, row := range g.Board.Cells {
+		for _, cell := range row {
+			if cell.Text == goals[0].Text {
+				found = cell.Target == 5
+			} else if cell.Target != 0 {
+				t.Errorf("Cell %q should have no target", cell.Text)
+			}
+		}
+	}
+	if !found {
+		t.Error("Generated cell should take the goal's target")
+	}
+}
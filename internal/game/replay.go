@@ -0,0 +1,143 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrNoReplay = errors.New("no recorded game start to replay from")
+
+// Replayer rebuilds game states by applying logged events in order
+// Start events carry a snapshot of the game, later events are applied on top of it,
+// and the game clock is brought up to each event's time like the room does
+type Replayer struct {
+	Game *Game // Replayed state, nil until a start event with a snapshot is applied
+
+	undoStack []*Game
+	redoStack []*Game
+}
+
+// Apply applies a single event to the replayed state
+func (p *Replayer) Apply(e Event) error {
+	if e.Action == ActionStart {
+		// Logs written before snapshots were recorded cannot be replayed
+		p.Game = nil
+		if e.Game != nil {
+			p.Game = e.Game.Clone()
+		}
+		p.undoStack, p.redoStack = nil, nil
+		return nil
+	}
+	if p.Game == nil {
+		return nil
+	}
+	if e.Action == ActionTimeout {
+		p.Game = p.Game.Clone()
+		p.Game.UpdateClock(e.Time)
+		return nil
+	}
+	if e.Action == ActionDispute || e.Action == ActionUpholdMark {
+		return nil
+	}
+
+	switch e.Action {
+	case ActionUndo:
+		if len(p.undoStack) == 0 {
+			return fmt.Errorf("event %d: %s without history", e.Seq, e.Action)
+		}
+		last := len(p.undoStack) - 1
+		p.redoStack = append(p.redoStack, p.Game)
+		p.Game = p.undoStack[last]
+		p.undoStack = p.undoStack[:last]
+	case ActionRedo:
+		if len(p.redoStack) == 0 {
+			return fmt.Errorf("event %d: %s without history", e.Seq, e.Action)
+		}
+		last := len(p.redoStack) - 1
+		p.undoStack = append(p.undoStack, p.Game)
+		p.Game = p.redoStack[last]
+		p.redoStack = p.redoStack[:last]
+	case ActionReset:
+		p.Game = p.Game.Clone()
+		p.Game.Reset()
+		p.undoStack, p.redoStack = nil, nil
+		return nil
+	default:
+		// The clock goes first like in Room.applyAction, so a countdown is over before the first mark
+		next := p.Game.Clone()
+		next.UpdateClock(e.Time)
+		if err := next.applyEvent(e); err != nil {
+			return fmt.Errorf("event %d: %s: %w", e.Seq, e.Action, err)
+		}
+		p.undoStack = append(p.undoStack, p.Game)
+		p.redoStack = nil
+		p.Game = next
+	}
+	p.Game.UpdateClock(e.Time)
+	return nil
+}
+
+// applyEvent applies an undoable game action from the log
+func (g *Game) applyEvent(e Event) error {
+	mark := Mark{Team: e.Team, PlayerID: e.ActorID, PlayerName: e.ActorName}
+
+	switch e.Action {
+	case ActionMark:
+		return g.MarkCellBy(e.Row, e.Col, mark)
+	case ActionForceMark:
+		return g.MarkCellForceBy(e.Row, e.Col, mark)
+	case ActionUnmark:
+		return g.UnmarkCell(e.Row, e.Col)
+	case ActionClearMark:
+		return g.ClearCellMark(e.Row, e.Col, e.Team)
+	case ActionSettle:
+		return g.Settle(e.Team)
+	case ActionClaim:
+		return g.ClaimCell(e.Row, e.Col, mark)
+	case ActionConfirmClaim:
+		return g.ConfirmClaim(e.Row, e.Col, e.Team)
+	case ActionRejectClaim:
+		return g.RejectClaim(e.Row, e.Col, e.Team)
+	case ActionRevertMark:
+		return g.RevertMark(e.Row, e.Col, e.Team)
+	case ActionProgressUp:
+		return g.AddProgress(e.Row, e.Col, mark, 1)
+	case ActionProgressDown:
+		return g.AddProgress(e.Row, e.Col, mark, -1)
+	case ActionLockCell:
+		return g.SetCellLock(e.Row, e.Col, CellLocked)
+	case ActionVoidCell:
+		return g.SetCellLock(e.Row, e.Col, CellVoid)
+	case ActionOpenCell:
+		return g.SetCellLock(e.Row, e.Col, CellOpen)
+	}
+	return fmt.Errorf("unknown action %q", e.Action)
+}
+
+// Replay rebuilds the game state from events, stopping before the first event for which stop returns true
+func Replay(events []Event, stop func(Event) bool) (*Game, error) {
+	var p Replayer
+	for _, e := range events {
+		if stop(e) {
+			break
+		}
+		if err := p.Apply(e); err != nil {
+			return nil, err
+		}
+	}
+	if p.Game == nil {
+		return nil, ErrNoReplay
+	}
+	return p.Game, nil
+}
+
+// ReplayToSeq rebuilds the game state right after the event with sequence number seq
+func ReplayToSeq(events []Event, seq int) (*Game, error) {
+	return Replay(events, func(e Event) bool { return e.Seq > seq })
+}
+
+// ReplayToTime rebuilds the game state as it was at time t
+func ReplayToTime(events []Event, t time.Time) (*Game, error) {
+	return Replay(events, func(e Event) bool { return e.Time.After(t) })
+}
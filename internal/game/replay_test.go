@@ -0,0 +1,104 @@
+package game
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// recordedPhaseMatch plays a short phase match and returns its event log
+func recordedPhaseMatch(t *testing.T) EventLog {
+	t.Helper()
+	g := NewGame(RulePhase)
+	g.PhaseConfig.UnlockThreshold = 1
+	g.Start()
+
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	var log EventLog
+	log.Append(Event{Time: base, Action: ActionStart, Game: g.Clone()})
+	at := func(sec int) time.Time { return base.Add(time.Duration(sec) * time.Second) }
+
+	log.Append(Event{Time: at(10), ActorID: "u1", ActorName: "alice", Action: ActionMark, Row: 0, Col: 0, Team: TeamRed})
+	log.Append(Event{Time: at(20), ActorID: "u2", ActorName: "bob", Action: ActionMark, Row: 0, Col: 1, Team: TeamBlue})
+	log.Append(Event{Time: at(30), ActorID: "u1", ActorName: "alice", Action: ActionMark, Row: 1, Col: 0, Team: TeamRed})
+	log.Append(Event{Time: at(40), ActorID: "ref", ActorName: "referee", Action: ActionUndo})
+	log.Append(Event{Time: at(50), ActorID: "ref", ActorName: "referee", Action: ActionRedo})
+	log.Append(Event{Time: at(60), ActorID: "ref", ActorName: "referee", Action: ActionUndo})
+	return log
+}
+
+func TestReplayToSeq(t *testing.T) {
+	log := recordedPhaseMatch(t)
+
+	g, err := ReplayToSeq(log, 4)
+	if err != nil {
+		t.Fatalf("ReplayToSeq failed: %v", err)
+	}
+	if !g.Board.Cells[1][0].HasMark(TeamRed) || g.TeamStates[TeamRed].RowMarks[1] != 1 {
+		t.Error("Third mark should be replayed")
+	}
+	if mark := g.Board.Cells[0][0].Marks[0]; mark.PlayerName != "alice" {
+		t.Errorf("Replayed mark should record the actor, got: %+v", mark)
+	}
+
+	g, _ = ReplayToSeq(log, 5)
+	if g.Board.Cells[1][0].HasMark(TeamRed) || g.TeamStates[TeamRed].RowMarks[1] != 0 {
+		t.Error("Undo should step the replayed state back")
+	}
+
+	g, _ = ReplayToSeq(log, 6)
+	if !g.Board.Cells[1][0].HasMark(TeamRed) {
+		t.Error("Redo should step the replayed state forward")
+	}
+
+	g, _ = ReplayToSeq(log, log.LastSeq())
+	if g.Board.Cells[1][0].HasMark(TeamRed) || !g.Board.Cells[0][1].HasMark(TeamBlue) {
+		t.Error("Replaying the whole log should end after the last undo")
+	}
+}
+
+func TestReplayToTime(t *testing.T) {
+	log := recordedPhaseMatch(t)
+	base := log[0].Time
+
+	g, err := ReplayToTime(log, base.Add(25*time.Second))
+	if err != nil {
+		t.Fatalf("ReplayToTime failed: %v", err)
+	}
+	if !g.Board.Cells[0][1].HasMark(TeamBlue) || g.Board.Cells[1][0].HasMark(TeamRed) {
+		t.Error("Replay should include exactly the events up to the given time")
+	}
+
+	if _, err := ReplayToTime(log, base.Add(-time.Second)); !errors.Is(err, ErrNoReplay) {
+		t.Errorf("Replay before the start should fail with ErrNoReplay, got: %v", err)
+	}
+}
+
+func TestReplayDoesNotChangeLog(t *testing.T) {
+	log := recordedPhaseMatch(t)
+	ReplayToSeq(log, log.LastSeq())
+
+	if log[0].Game.Board.Cells[0][0].HasMark(TeamRed) {
+		t.Error("Replay should not modify the start snapshot")
+	}
+}
+
+func TestReplayAfterCountdown(t *testing.T) {
+	g := NewGameWithSize(RuleNormal, 3)
+	g.SetCountdown(10 * time.Second)
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	g.StartAt(base)
+
+	var log EventLog
+	log.Append(Event{Time: base, Action: ActionStart, Game: g.Clone()})
+	log.Append(Event{Time: base.Add(15 * time.Second), ActorID: "u1", ActorName: "alice", Action: ActionMark, Row: 0, Col: 0, Team: TeamRed})
+
+	// The snapshot is still counting down, the mark comes after the reveal
+	replayed, err := ReplayToSeq(log, log.LastSeq())
+	if err != nil {
+		t.Fatalf("Replay after the countdown failed: %v", err)
+	}
+	if replayed.Status != StatusPlaying || !replayed.Board.Cells[0][0].HasMark(TeamRed) {
+		t.Errorf("Mark after the countdown should be replayed, got status %v", replayed.Status)
+	}
+}
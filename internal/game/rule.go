@@ -0,0 +1,292 @@
+package game
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+)
+
+// Rule implements how a game rule marks cells, tracks progress and decides the game
+// Rules are stateless, per-game state lives in the Game they are given
+type Rule interface {
+	// Name is the rule's identifier in the protocol, e.g. "normal"
+	Name() string
+
+	// Mark validates a team's mark on a cell and applies it
+	Mark(g *Game, row, col int, mark Mark) error
+
+	// Unmarked updates rule state after marks were removed from a cell
+	Unmarked(g *Game, row, col int, removed []Mark)
+
+	// CheckWin returns the winner the board decides, nil while the game goes on
+	CheckWin(g *Game) *Winner
+
+	// Scores returns every team's score, used to decide games that end early
+	Scores(g *Game) map[TeamID]int
+
+	// TieBreak returns the team that wins a tie on Scores, TeamNone for a draw
+	TieBreak(g *Game) TeamID
+
+	// Settle settles a team in rules that end once every team has settled,
+	// others return ErrNoSettlement
+	Settle(g *Game, team TeamID) error
+
+	// RefereeOverrides reports whether a referee's mark replaces the marks on a cell
+	// instead of being added like a team's mark
+	RefereeOverrides() bool
+
+	// ConfigSchema describes the config fields the rule reads
+	ConfigSchema() []ConfigField
+}
+
+// ConfigField describes a rule setting, Key matches the field's JSON name
+type ConfigField struct {
+	Key         string `json:"key"`
+	Type        string `json:"type"` // One of the Config* types below
+	Min         int    `json:"min"`
+	Description string `json:"description"`
+}
+
+// checkMin returns a *ConfigError for path if value is below the field's minimum
+func (f ConfigField) checkMin(path string, value int) error {
+	if value >= f.Min {
+		return nil
+	}
+	if f.Min == 0 {
+		return &ConfigError{Field: path, Code: ConfigErrNegative, Message: fmt.Sprintf("must not be negative, got %d", value), Err: ErrInvalidPhaseConfig}
+	}
+	return &ConfigError{Field: path, Code: ConfigErrTooSmall, Message: fmt.Sprintf("must be at least %d, got %d", f.Min, value), Err: ErrInvalidPhaseConfig}
+}
+
+// Config field types
+const (
+	ConfigInt       = "int"
+	ConfigIntPerRow = "int_per_row"
+	ConfigPhases    = "phases" // List of {cells, score, later_score, limit, unlock_threshold}
+)
+
+// rules holds every known rule by ID
+var rules = map[GameRule]Rule{
+	RuleNormal:   normalRule{},
+	RuleBlackout: blackoutRule{},
+	RulePhase:    phaseRule{},
+	RulePoints:   pointsRule{},
+}
+
+// RegisterRule makes a rule available under an unused ID and name
+// Call it from an init function, the registry is not safe for concurrent changes
+func RegisterRule(id GameRule, rule Rule) {
+	if _, ok := rules[id]; ok {
+		panic(fmt.Sprintf("game: rule ID %d registered twice", id))
+	}
+	if _, ok := LookupRule(rule.Name()); ok {
+		panic(fmt.Sprintf("game: rule %q registered twice", rule.Name()))
+	}
+	rules[id] = rule
+}
+
+// LookupRule finds a registered rule by name
+func LookupRule(name string) (GameRule, bool) {
+	for id, rule := range rules {
+		if rule.Name() == name {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// Rules returns the IDs of all registered rules in order
+func Rules() []GameRule {
+	ids := make([]GameRule, 0, len(rules))
+	for id := range rules {
+		ids = append(ids, id)
+	}
+	slices.SortFunc(ids, cmp.Compare)
+	return ids
+}
+
+// Impl returns the rule's implementation, unknown IDs play by the normal rule
+func (r GameRule) Impl() Rule {
+	if rule, ok := rules[r]; ok {
+		return rule
+	}
+	return normalRule{}
+}
+
+// RefereeOverrides reports whether the game's rule lets a referee's mark replace the marks on a cell
+func (g *Game) RefereeOverrides() bool {
+	return g.rule().RefereeOverrides()
+}
+
+// rule returns the implementation of the game's rule
+func (g *Game) rule() Rule {
+	return g.Rule.Impl()
+}
+
+// normalRule: each cell can only be marked once, first line or full board wins
+type normalRule struct{}
+
+func (normalRule) Name() string { return "normal" }
+
+func (normalRule) Mark(g *Game, row, col int, mark Mark) error {
+	return g.markNormal(&g.Board.Cells[row][col], mark)
+}
+
+func (normalRule) Unmarked(g *Game, row, col int, removed []Mark) {}
+
+func (normalRule) CheckWin(g *Game) *Winner {
+	return g.checkNormalWin()
+}
+
+func (normalRule) Scores(g *Game) map[TeamID]int {
+	return g.markScores()
+}
+
+func (normalRule) Settle(g *Game, team TeamID) error {
+	return ErrNoSettlement
+}
+
+func (normalRule) RefereeOverrides() bool {
+	return true
+}
+
+func (normalRule) TieBreak(g *Game) TeamID {
+	return TeamNone
+}
+
+func (normalRule) ConfigSchema() []ConfigField {
+	return nil
+}
+
+// blackoutRule: every team can mark every cell once, first to mark them all wins
+type blackoutRule struct{}
+
+func (blackoutRule) Name() string { return "blackout" }
+
+func (blackoutRule) Mark(g *Game, row, col int, mark Mark) error {
+	return g.markBlackout(&g.Board.Cells[row][col], mark)
+}
+
+func (blackoutRule) Unmarked(g *Game, row, col int, removed []Mark) {}
+
+func (blackoutRule) CheckWin(g *Game) *Winner {
+	return g.checkBlackoutWin()
+}
+
+func (blackoutRule) Scores(g *Game) map[TeamID]int {
+	return g.markScores()
+}
+
+func (blackoutRule) Settle(g *Game, team TeamID) error {
+	return ErrNoSettlement
+}
+
+func (blackoutRule) RefereeOverrides() bool {
+	return false
+}
+
+func (blackoutRule) TieBreak(g *Game) TeamID {
+	return TeamNone
+}
+
+func (blackoutRule) ConfigSchema() []ConfigField {
+	return nil
+}
+
+// phaseRule: rows unlock one by one with per-row limits, decided by score once every team settled
+type phaseRule struct{}
+
+func (phaseRule) Name() string { return "phase" }
+
+func (phaseRule) Mark(g *Game, row, col int, mark Mark) error {
+	return g.markPhase(row, col, mark)
+}
+
+func (phaseRule) Unmarked(g *Game, row, col int, removed []Mark) {
+	phase := g.phaseOf(row, col)
+	if phase < 0 {
+		return
+	}
+	for _, m := range removed {
+		state, ok := g.TeamStates[m.Team]
+		if !ok {
+			continue
+		}
+		if state.RowMarks[phase] > 0 {
+			state.RowMarks[phase]--
+		}
+		g.recheckPhaseRowUnlock(m.Team)
+	}
+	g.recheckPhaseBingo()
+}
+
+// CheckWin keeps the current result: phase games end by settlement, not by the board
+func (phaseRule) CheckWin(g *Game) *Winner {
+	return g.Winner
+}
+
+func (phaseRule) Scores(g *Game) map[TeamID]int {
+	return g.CalculatePhaseScore()
+}
+
+func (phaseRule) Settle(g *Game, team TeamID) error {
+	return g.settlePhase(team)
+}
+
+func (phaseRule) RefereeOverrides() bool {
+	return false
+}
+
+func (phaseRule) TieBreak(g *Game) TeamID {
+	return g.FirstSettler
+}
+
+func (phaseRule) ConfigSchema() []ConfigField {
+	return []ConfigField{
+		{Key: "row_scores", Type: ConfigIntPerRow, Min: 0, Description: "Score for the first mark on a cell of each row"},
+		{Key: "second_half_scores", Type: ConfigIntPerRow, Min: 0, Description: "Score for later marks on a cell of each row"},
+		{Key: "cells_per_row", Type: ConfigInt, Min: 1, Description: "Cells each team can mark per row"},
+		{Key: "unlock_threshold", Type: ConfigInt, Min: 1, Description: "Marks in a row needed to unlock the next one"},
+		{Key: "bingo_bonus", Type: ConfigInt, Min: 0, Description: "Bonus for the first line"},
+		{Key: "final_bonus", Type: ConfigInt, Min: 0, Description: "Bonus for settling first"},
+		{Key: "phases", Type: ConfigPhases, Min: 0, Description: "Phases as cell groups with their own scores, limit and unlock threshold, replacing the per-row fields"},
+	}
+}
+
+// pointsRule: each cell can only be marked once and is worth its own points,
+// first to the target (or most points on a full board or at the time limit) wins
+type pointsRule struct{}
+
+func (pointsRule) Name() string { return "points" }
+
+func (pointsRule) Mark(g *Game, row, col int, mark Mark) error {
+	return g.markNormal(&g.Board.Cells[row][col], mark)
+}
+
+func (pointsRule) Unmarked(g *Game, row, col int, removed []Mark) {}
+
+func (pointsRule) CheckWin(g *Game) *Winner {
+	return g.checkPointsWin()
+}
+
+func (pointsRule) Scores(g *Game) map[TeamID]int {
+	return g.addScoreOffsets(g.CountPoints())
+}
+
+func (pointsRule) Settle(g *Game, team TeamID) error {
+	return ErrNoSettlement
+}
+
+func (pointsRule) RefereeOverrides() bool {
+	return true
+}
+
+func (pointsRule) TieBreak(g *Game) TeamID {
+	return TeamNone
+}
+
+func (pointsRule) ConfigSchema() []ConfigField {
+	return []ConfigField{
+		{Key: "points_target", Type: ConfigInt, Min: 0, Description: "Score that wins, 0 plays until the board is full or time runs out"},
+	}
+}
@@ -0,0 +1,117 @@
+package game
+
+import (
+	"errors"
+	"testing"
+)
+
+// firstMarkRule is a house rule where the first mark wins
+type firstMarkRule struct{ normalRule }
+
+func (firstMarkRule) Name() string { return "first_mark" }
+
+func (firstMarkRule) CheckWin(g *Game) *Winner {
+	counts := g.CountMarks()
+	for _, t := range g.Teams {
+		if counts[t.ID] > 0 {
+			return &Winner{Winner: t.ID, Reason: WinReasonBingo, Scores: counts}
+		}
+	}
+	return nil
+}
+
+const ruleFirstMark GameRule = 100
+
+func init() {
+	RegisterRule(ruleFirstMark, firstMarkRule{})
+}
+
+func TestRuleRegistry(t *testing.T) {
+	for _, name := range []string{"normal", "blackout", "phase", "first_mark"} {
+		if got := GameRuleFromString(name).String(); got != name {
+			t.Errorf("GameRuleFromString(%q) round trips to %q", name, got)
+		}
+	}
+	if _, ok := LookupRule("nonsense"); ok {
+		t.Error("Unknown rule name should not be found")
+	}
+	if GameRuleFromString("nonsense") != RuleNormal {
+		t.Error("Unknown rule name should fall back to normal")
+	}
+
+	rules := Rules()
+	if len(rules) < 4 || rules[0] != RuleNormal || rules[len(rules)-1] != ruleFirstMark {
+		t.Errorf("Rules should list registered rules in order, got %v", rules)
+	}
+}
+
+func TestRegisterRuleTwice(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Registering a rule name twice should panic")
+		}
+	}()
+	RegisterRule(101, firstMarkRule{})
+}
+
+func TestHouseRule(t *testing.T) {
+	g := NewGame(ruleFirstMark)
+	g.Start()
+
+	g.MarkCell(2, 2, TeamBlue)
+	if g.Status != StatusFinished || g.Winner == nil || g.Winner.Winner != TeamBlue {
+		t.Errorf("House rule should decide the game, got status %v winner %+v", g.Status, g.Winner)
+	}
+
+	g.UnmarkCell(2, 2)
+	if g.Status != StatusPlaying || g.Winner != nil {
+		t.Error("Removing the mark should reopen the game")
+	}
+}
+
+func TestRefereeOverrides(t *testing.T) {
+	want := map[GameRule]bool{RuleNormal: true, RuleBlackout: false, RulePhase: false, RulePoints: true, ruleFirstMark: true}
+	for rule, overrides := range want {
+		if g := NewGame(rule); g.RefereeOverrides() != overrides {
+			t.Errorf("%s rule: referee overrides should be %v", rule, overrides)
+		}
+	}
+}
+
+func TestSettleByRule(t *testing.T) {
+	for _, rule := range []GameRule{RuleNormal, RuleBlackout, RulePoints, ruleFirstMark} {
+		g := NewGame(rule)
+		g.Start()
+		if err := g.Settle(TeamRed); err != ErrNoSettlement {
+			t.Errorf("%s rule should have no settlement, got: %v", rule, err)
+		}
+	}
+}
+
+func TestPhaseConfigSchema(t *testing.T) {
+	schema := RulePhase.Impl().ConfigSchema()
+	if len(schema) != 7 || schema[0].Key != "row_scores" || schema[0].Type != ConfigIntPerRow {
+		t.Errorf("Unexpected phase schema: %+v", schema)
+	}
+	if len(RuleNormal.Impl().ConfigSchema()) != 0 {
+		t.Error("Normal rule should have no config")
+	}
+
+	// Validate takes its minimums from the schema, single values even with phases
+	var configErr *ConfigError
+	config := DefaultPhaseConfig(5)
+	config.Phases = ringPhases()
+	config.CellsPerRow = 0
+	if err := config.Validate(5); !errors.As(err, &configErr) || configErr.Field != "cells_per_row" || configErr.Code != ConfigErrTooSmall {
+		t.Errorf("Zero cells per row should be too small, got: %v", err)
+	}
+	config = DefaultPhaseConfig(5)
+	config.BingoBonus = 0
+	if err := config.Validate(5); err != nil {
+		t.Errorf("Zero bonus should be allowed, got: %v", err)
+	}
+	config.FinalBonus = -1
+	if err := config.Validate(5); !errors.As(err, &configErr) || configErr.Field != "final_bonus" || configErr.Code != ConfigErrNegative {
+		t.Errorf("Negative bonus should fail, got: %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// MaxBestOf caps the number of games in a series
+const MaxBestOf = 15
+
+var (
+	ErrInvalidBestOf = errors.New("invalid series length")
+	ErrSeriesOver    = errors.New("series is already decided")
+)
+
+// Series is a best-of-N match played as consecutive games in a room
+type Series struct {
+	BestOf int            `json:"best_of"`          // Games at most, a team needs more than half of them to win
+	Games  []Winner       `json:"games,omitempty"`  // Results of the games played so far, in order
+	Wins   map[TeamID]int `json:"wins,omitempty"`   // Games won per team
+	Over   bool           `json:"over,omitempty"`   // Whether the series is decided
+	Winner TeamID         `json:"winner,omitempty"` // Team that won the series, empty while running or on a tie
+
+	// Disputes raised in each closed game, in the order of Games
+	Disputes [][]Dispute `json:"disputes,omitempty"`
+
+	// Whether the last game is the room's current one, so an undo can still take it back
+	Pending bool `json:"pending,omitempty"`
+}
+
+// NewSeries starts a best-of-N series
+func NewSeries(bestOf int) (*Series, error) {
+	if bestOf < 1 || bestOf > MaxBestOf {
+		return nil, fmt.Errorf("%w: best of %d, must be 1 to %d", ErrInvalidBestOf, bestOf, MaxBestOf)
+	}
+	return &Series{BestOf: bestOf, Wins: make(map[TeamID]int)}, nil
+}
+
+// WinsNeeded returns how many game wins decide the series
+func (s *Series) WinsNeeded() int {
+	return s.BestOf/2 + 1
+}
+
+// Record adds a finished game's result to the series
+// Draws count as played games without a win, so after BestOf games
+// the team with the most wins takes the series, or nobody on a tie
+func (s *Series) Record(w *Winner) error {
+	if s.Over {
+		return ErrSeriesOver
+	}
+	if w == nil {
+		return errors.New("game has no result")
+	}
+
+	result := *w
+	result.Scores = maps.Clone(w.Scores)
+	result.Cells = nil // The winning cells only matter on the board they were played on
+	s.Games = append(s.Games, result)
+
+	if w.Winner != TeamNone {
+		if s.Wins == nil {
+			s.Wins = make(map[TeamID]int)
+		}
+		s.Wins[w.Winner]++
+		if s.Wins[w.Winner] >= s.WinsNeeded() {
+			s.Over = true
+			s.Winner = w.Winner
+			return nil
+		}
+	}
+
+	if len(s.Games) >= s.BestOf {
+		s.Over = true
+		s.Winner = s.leader()
+	}
+	return nil
+}
+
+// leader returns the team with the most wins, TeamNone on a tie
+func (s *Series) leader() TeamID {
+	leader, best, tied := TeamNone, 0, false
+	for team, wins := range s.Wins {
+		switch {
+		case wins > best:
+			leader, best, tied = team, wins, false
+		case wins == best:
+			tied = true
+		}
+	}
+	if tied {
+		return TeamNone
+	}
+	return leader
+}
+
+// Running reports whether there is a series that is not decided yet
+func (s *Series) Running() bool {
+	return s != nil && !s.Over
+}
+
+// SyncGame keeps the series in step with the room's current game: its result is recorded
+// as soon as it has finished, and taken back out or replaced when an undo reopens or changes it
+// The result stays pending until CloseGame
+func (s *Series) SyncGame(g *Game) {
+	if s == nil {
+		return
+	}
+	if s.Pending {
+		s.unrecord()
+	}
+	if !s.Over && g.Status == StatusFinished && g.Winner != nil {
+		s.Record(g.Winner)
+		s.Pending = true
+	}
+}
+
+// CloseGame keeps the pending result for good, when the room moves on to the next game,
+// archiving the disputes raised in that game with it
+func (s *Series) CloseGame(disputes Disputes) {
+	if s == nil || !s.Pending {
+		return
+	}
+	s.Disputes = append(s.Disputes, slices.Clone(disputes))
+	s.Pending = false
+}
+
+// unrecord drops the last recorded game, counting the wins again from the others
+// Only a pending game is dropped, so the archived disputes stay as they are
+func (s *Series) unrecord() {
+	if len(s.Games) == 0 {
+		return
+	}
+	games := s.Games[:len(s.Games)-1]
+	*s = Series{BestOf: s.BestOf, Wins: make(map[TeamID]int), Disputes: s.Disputes}
+	for i := range games {
+		s.Record(&games[i])
+	}
+}
+
+// Clone returns a deep copy of the series
+func (s *Series) Clone() *Series {
+	if s == nil {
+		return nil
+	}
+	c := *s
+	c.Games = make([]Winner, len(s.Games))
+	for i, w := range s.Games {
+		w.Scores = maps.Clone(w.Scores)
+		w.Handicaps = cloneHandicaps(w.Handicaps)
+		c.Games[i] = w
+	}
+	c.Disputes = make([][]Dispute, len(s.Disputes))
+	for i, disputes := range s.Disputes {
+		c.Disputes[i] = slices.Clone(disputes)
+	}
+	c.Wins = maps.Clone(s.Wins)
+	return &c
+}
@@ -0,0 +1,97 @@
+package game
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSeriesBestOf3(t *testing.T) {
+	s, err := NewSeries(3)
+	if err != nil {
+		t.Fatalf("NewSeries failed: %v", err)
+	}
+	if s.WinsNeeded() != 2 {
+		t.Errorf("Best of 3 needs 2 wins, got %d", s.WinsNeeded())
+	}
+
+	s.Record(&Winner{Winner: TeamRed, Reason: WinReasonBingo, Scores: map[TeamID]int{TeamRed: 5, TeamBlue: 3}})
+	s.Record(&Winner{Winner: TeamBlue, Reason: WinReasonBingo})
+	if s.Over {
+		t.Fatal("Series should go on at 1-1")
+	}
+	s.Record(&Winner{Winner: TeamRed, Reason: WinReasonTimeout})
+	if !s.Over || s.Winner != TeamRed || s.Wins[TeamRed] != 2 || s.Wins[TeamBlue] != 1 {
+		t.Errorf("Red should win the series 2-1, got %+v", s)
+	}
+	if s.Games[0].Scores[TeamRed] != 5 {
+		t.Errorf("Game scores should be kept, got %v", s.Games[0].Scores)
+	}
+	if err := s.Record(&Winner{Winner: TeamBlue}); err != ErrSeriesOver {
+		t.Errorf("Decided series should take no more games, got: %v", err)
+	}
+}
+
+func TestSeriesDraws(t *testing.T) {
+	s, _ := NewSeries(3)
+	s.Record(&Winner{Winner: TeamNone, Reason: WinReasonTimeout})
+	s.Record(&Winner{Winner: TeamRed, Reason: WinReasonBingo})
+	s.Record(&Winner{Winner: TeamBlue, Reason: WinReasonBingo})
+	if !s.Over || s.Winner != TeamNone {
+		t.Errorf("Series with a draw should end tied after 3 games, got %+v", s)
+	}
+
+	s, _ = NewSeries(3)
+	s.Record(&Winner{Winner: TeamNone})
+	s.Record(&Winner{Winner: TeamNone})
+	s.Record(&Winner{Winner: TeamBlue})
+	if !s.Over || s.Winner != TeamBlue {
+		t.Errorf("Blue has the most wins after 3 games, got %+v", s)
+	}
+}
+
+func TestSeriesSyncGame(t *testing.T) {
+	s, _ := NewSeries(3)
+	g := NewGameWithSize(RuleNormal, 3)
+	g.Start()
+	s.SyncGame(g)
+	if len(s.Games) != 0 {
+		t.Errorf("Running game should not count, got %+v", s)
+	}
+
+	g.MarkCell(0, 0, TeamRed)
+	g.MarkCell(0, 1, TeamRed)
+	before := g.Clone()
+	g.MarkCell(0, 2, TeamRed)
+	s.SyncGame(g)
+	s.SyncGame(g)
+	if len(s.Games) != 1 || s.Wins[TeamRed] != 1 || !s.Pending {
+		t.Fatalf("Finished game should be recorded once, got %+v", s)
+	}
+
+	// Undoing the finishing mark takes the result back out
+	s.SyncGame(before)
+	if len(s.Games) != 0 || s.Wins[TeamRed] != 0 || s.Pending {
+		t.Errorf("Reopened game should not count, got %+v", s)
+	}
+
+	// Once closed, the result stays when the next game starts, along with its disputes
+	s.SyncGame(g)
+	s.CloseGame(Disputes{{ID: 1, Row: 0, Col: 2, Team: TeamRed, By: TeamBlue, Status: DisputeUpheld}})
+	g.Reset()
+	s.SyncGame(g)
+	if len(s.Games) != 1 || s.Wins[TeamRed] != 1 || !s.Running() {
+		t.Errorf("Closed game should stay recorded, got %+v", s)
+	}
+	if len(s.Disputes) != 1 || len(s.Disputes[0]) != 1 || s.Disputes[0][0].Status != DisputeUpheld {
+		t.Errorf("Disputes should be archived for the game, got %+v", s.Disputes)
+	}
+
+	var none *Series
+	none.SyncGame(g)
+	if none.Running() {
+		t.Error("No series should not be running")
+	}
+	if _, err := NewSeries(0); !errors.Is(err, ErrInvalidBestOf) {
+		t.Errorf("Best of 0 should fail, got: %v", err)
+	}
+}
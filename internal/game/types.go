@@ -1,109 +1,236 @@
 package game
 
-// PlayerColor represents the color of a player
-type PlayerColor int
+import "time"
+
+// TeamID identifies a team within a game
+type TeamID string
 
 const (
-	ColorNone PlayerColor = iota
-	ColorRed
-	ColorBlue
+	TeamNone TeamID = ""
+	TeamRed  TeamID = "red"  // ID of the first default team
+	TeamBlue TeamID = "blue" // ID of the second default team
 )
 
-func (c PlayerColor) String() string {
-	switch c {
-	case ColorRed:
-		return "red"
-	case ColorBlue:
-		return "blue"
-	default:
+func (t TeamID) String() string {
+	if t == TeamNone {
 		return "none"
 	}
+	return string(t)
 }
 
-func PlayerColorFromString(s string) PlayerColor {
-	switch s {
-	case "red":
-		return ColorRed
-	case "blue":
-		return ColorBlue
-	default:
-		return ColorNone
+func TeamIDFromString(s string) TeamID {
+	if s == "none" {
+		return TeamNone
 	}
+	return TeamID(s)
 }
 
-// GameRule represents the type of game rule
+// Team count limits
+const (
+	MinTeams = 2
+	MaxTeams = 8
+)
+
+// Team represents a team taking part in the game
+type Team struct {
+	ID    TeamID `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"` // Display color, e.g. "#e74c3c"
+}
+
+// DefaultTeams returns the classic red vs blue setup
+func DefaultTeams() []Team {
+	return []Team{
+		{ID: TeamRed, Name: "Red", Color: "#e74c3c"},
+		{ID: TeamBlue, Name: "Blue", Color: "#3498db"},
+	}
+}
+
+// TeamState holds per-team progress for phase rule
+type TeamState struct {
+	RowMarks    []int `json:"row_marks"`    // Marks per phase (a row unless PhaseConfig.Phases is set)
+	UnlockedRow int   `json:"unlocked_row"` // Highest phase unlocked
+	Settled     bool  `json:"settled"`      // Whether the team has settled
+}
+
+// newTeamState creates an empty team state for a number of phases
+func newTeamState(phases int) *TeamState {
+	return &TeamState{RowMarks: make([]int, phases)}
+}
+
+// GameRule identifies a registered Rule (see RegisterRule)
+// IDs are persisted with games, so new rules take unused values
 type GameRule int
 
 const (
-	RuleNormal GameRule = iota // Normal rule: each cell can only be marked once
-	RuleBlackout               // Blackout: allow duplicate marks, record times
-	RulePhase                  // Phase rule: row-by-row with limits and scoring
+	RuleNormal   GameRule = iota // Normal rule: each cell can only be marked once
+	RuleBlackout                 // Blackout: allow duplicate marks, record times
+	RulePhase                    // Phase rule: row-by-row with limits and scoring
+	RulePoints                   // Points race: cells are worth their own points
 )
 
 func (r GameRule) String() string {
-	switch r {
-	case RuleNormal:
-		return "normal"
-	case RuleBlackout:
-		return "blackout"
-	case RulePhase:
-		return "phase"
-	default:
-		return "unknown"
+	if rule, ok := rules[r]; ok {
+		return rule.Name()
 	}
+	return "unknown"
 }
 
+// GameRuleFromString finds a registered rule by name, unknown names give the normal rule
 func GameRuleFromString(s string) GameRule {
-	switch s {
-	case "normal":
-		return RuleNormal
-	case "blackout":
-		return RuleBlackout
-	case "phase":
-		return RulePhase
-	default:
-		return RuleNormal
+	if id, ok := LookupRule(s); ok {
+		return id
 	}
+	return RuleNormal
 }
 
+// Board size limits
+const (
+	MinBoardSize     = 3
+	MaxBoardSize     = 9
+	DefaultBoardSize = 5
+)
+
 // PhaseConfig holds configuration for phase rule
 type PhaseConfig struct {
-	RowScores        [5]int `json:"row_scores"`         // A[n]: Score per row, default: [2, 2, 4, 4, 6]
-	SecondHalfScores [5]int `json:"second_half_scores"` // B[n]: Score for second player, default: [1, 1, 2, 2, 3]
-	CellsPerRow      int    `json:"cells_per_row"`      // C: Max cells each player can mark per row, default: 3
-	UnlockThreshold  int    `json:"unlock_threshold"`   // D: Cells needed to unlock next row, default: 2
-	BingoBonus       int    `json:"bingo_bonus"`        // E: Bonus for first Bingo, default: 3
-	FinalBonus       int    `json:"final_bonus"`        // F: Bonus for first settlement, default: 3
+	RowScores        []int `json:"row_scores"`         // A[n]: Score per row, default: [2, 2, 4, 4, 6]
+	SecondHalfScores []int `json:"second_half_scores"` // B[n]: Score for second player, default: [1, 1, 2, 2, 3]
+	CellsPerRow      int   `json:"cells_per_row"`      // C: Max cells each player can mark per row, default: 3
+	UnlockThreshold  int   `json:"unlock_threshold"`   // D: Cells needed to unlock next row, default: 2
+	BingoBonus       int   `json:"bingo_bonus"`        // E: Bonus for first Bingo, default: 3
+	FinalBonus       int   `json:"final_bonus"`        // F: Bonus for first settlement, default: 3
+
+	// Phases as cell groups, replacing one phase per row (and the per-row fields above) when set
+	Phases []Phase `json:"phases,omitempty"`
+}
+
+// Phase is a group of cells unlocked together in phase rule
+type Phase struct {
+	Cells           [][2]int `json:"cells"`            // Cells as [row, col]
+	Score           int      `json:"score"`            // Score for the first mark on a cell
+	LaterScore      int      `json:"later_score"`      // Score for later marks on a cell
+	Limit           int      `json:"limit"`            // Cells each team can mark in the phase
+	UnlockThreshold int      `json:"unlock_threshold"` // Marks needed to unlock the next phase
 }
 
-// DefaultPhaseConfig returns the default phase configuration
-func DefaultPhaseConfig() PhaseConfig {
+// DefaultPhaseConfig returns the default phase configuration for a board size
+// Row scores follow the 5x5 pattern: every two rows the score goes up a step
+func DefaultPhaseConfig(size int) PhaseConfig {
+	rowScores := make([]int, size)
+	secondHalfScores := make([]int, size)
+	for i := 0; i < size; i++ {
+		rowScores[i] = 2 * (i/2 + 1)
+		secondHalfScores[i] = i/2 + 1
+	}
 	return PhaseConfig{
-		RowScores:        [5]int{2, 2, 4, 4, 6},
-		SecondHalfScores: [5]int{1, 1, 2, 2, 3},
-		CellsPerRow:      3,
+		RowScores:        rowScores,
+		SecondHalfScores: secondHalfScores,
+		CellsPerRow:      (size + 1) / 2,
 		UnlockThreshold:  2,
 		BingoBonus:       3,
 		FinalBonus:       3,
 	}
 }
 
+// Mark represents a single team's mark on a cell
+type Mark struct {
+	Team       TeamID `json:"team"`
+	PlayerID   string `json:"player_id,omitempty"`   // Team member (or referee) who made the mark
+	PlayerName string `json:"player_name,omitempty"` // Name at the time of marking, users are not persisted
+	Counted    bool   `json:"counted,omitempty"`     // Made by the cell's counter reaching its target
+}
+
 // Cell represents a single cell on the board
 type Cell struct {
-	MarkedBy   PlayerColor `json:"marked_by"`   // Which player marked this cell first
-	SecondMark PlayerColor `json:"second_mark"` // Which player marked this cell second (for phase rule)
-	Times      int         `json:"times"`       // How many times marked (for blackout/phase)
-	Text       string      `json:"text"`        // Text displayed in the cell
+	Marks   []Mark   `json:"marks,omitempty"`   // Marks in the order they were made, first marker first
+	Claims  []Mark   `json:"claims,omitempty"`  // Pending claims awaiting a referee, not counted as marks
+	Intents []Mark   `json:"intents,omitempty"` // Players working on the cell, cleared once it is marked
+	Times   int      `json:"times"`             // How many times marked (for blackout/phase)
+	Points  int      `json:"points,omitempty"`  // Point value in points rule, 0 means 1
+	Text    string   `json:"text"`              // Text displayed in the cell
+	Lock    CellLock `json:"lock,omitempty"`    // Referee lock, void cells are free spaces in line checks
+
+	// Count each team has to reach for goals like "collect 5 X", 0 for none,
+	// and each team's counter towards it; reaching the target marks the cell
+	Target   int            `json:"target,omitempty"`
+	Progress map[TeamID]int `json:"progress,omitempty"`
+}
+
+// MarkedBy returns the team that marked this cell first
+func (c *Cell) MarkedBy() TeamID {
+	if len(c.Marks) == 0 {
+		return TeamNone
+	}
+	return c.Marks[0].Team
+}
+
+// SecondMark returns the team that marked this cell second
+func (c *Cell) SecondMark() TeamID {
+	if len(c.Marks) < 2 {
+		return TeamNone
+	}
+	return c.Marks[1].Team
+}
+
+// HasMark reports whether the team has marked this cell
+func (c *Cell) HasMark(team TeamID) bool {
+	return c.markIndex(team) >= 0
+}
+
+// markIndex returns the position of the team's mark, or -1
+func (c *Cell) markIndex(team TeamID) int {
+	for i, m := range c.Marks {
+		if m.Team == team {
+			return i
+		}
+	}
+	return -1
+}
+
+// removeMark removes the team's mark, later marks move up one place
+func (c *Cell) removeMark(team TeamID) bool {
+	i := c.markIndex(team)
+	if i < 0 {
+		return false
+	}
+	c.Marks = append(c.Marks[:i], c.Marks[i+1:]...)
+	return true
 }
 
-// Board represents the 5x5 bingo board
+// Board represents the NxN bingo board
 type Board struct {
-	Cells [5][5]Cell `json:"cells"`
+	Cells [][]Cell `json:"cells"`
 }
 
-// NewBoard creates a new empty board
-func NewBoard() *Board {
-	return &Board{}
+// NewBoard creates a new empty board of the given size
+func NewBoard(size int) *Board {
+	cells := make([][]Cell, size)
+	for i := range cells {
+		cells[i] = make([]Cell, size)
+	}
+	return &Board{Cells: cells}
+}
+
+// Size returns the number of rows (and columns) of the board
+func (b *Board) Size() int {
+	return len(b.Cells)
+}
+
+// InBounds reports whether the position is on the board
+func (b *Board) InBounds(row, col int) bool {
+	size := b.Size()
+	return row >= 0 && row < size && col >= 0 && col < size
+}
+
+// Texts returns the cell texts in row-major order
+func (b *Board) Texts() []string {
+	texts := make([]string, 0, b.Size()*b.Size())
+	for _, row := range b.Cells {
+		for _, cell := range row {
+			texts = append(texts, cell.Text)
+		}
+	}
+	return texts
 }
 
 // GameStatus represents the current status of the game
@@ -113,6 +240,7 @@ const (
 	StatusWaiting GameStatus = iota
 	StatusPlaying
 	StatusFinished
+	StatusCountdown // Between waiting and playing, board hidden until StartTime (appended to keep persisted values)
 )
 
 func (s GameStatus) String() string {
@@ -123,6 +251,8 @@ func (s GameStatus) String() string {
 		return "playing"
 	case StatusFinished:
 		return "finished"
+	case StatusCountdown:
+		return "countdown"
 	default:
 		return "unknown"
 	}
@@ -135,39 +265,64 @@ const (
 	WinReasonBingo     WinReason = "bingo"
 	WinReasonFullBoard WinReason = "full_board"
 	WinReasonBlackout  WinReason = "blackout"
-	WinReasonPhase     WinReason = "phase" // Phase rule: settlement complete
+	WinReasonPhase     WinReason = "phase"   // Phase rule: settlement complete
+	WinReasonTimeout   WinReason = "timeout" // Time limit ran out, decided by the rule's scoring
+	WinReasonPoints    WinReason = "points"  // Points rule: target score reached
 )
 
 // Winner represents the game result
 type Winner struct {
-	Winner    PlayerColor `json:"winner"`
-	Reason    WinReason   `json:"reason"`
-	RedScore  int         `json:"red_score"`
-	BlueScore int         `json:"blue_score"`
+	Winner TeamID         `json:"winner"`
+	Reason WinReason      `json:"reason"`
+	Scores map[TeamID]int `json:"scores"`
+
+	// Winning shape in normal rule and its cells, as [row, col]
+	Pattern string   `json:"pattern,omitempty"`
+	Cells   [][2]int `json:"cells,omitempty"`
+
+	// Team handicaps the game was played with, Scores include their offsets
+	Handicaps []Handicap `json:"handicaps,omitempty"`
 }
 
 // Game represents a complete game state
 type Game struct {
-	Board       *Board      `json:"board"`
-	Rule        GameRule    `json:"rule"`
-	PhaseConfig PhaseConfig `json:"phase_config"`
-	Status      GameStatus  `json:"status"`
-	Winner      *Winner     `json:"winner,omitempty"`
+	Board        *Board      `json:"board"`
+	Rule         GameRule    `json:"rule"`
+	PhaseConfig  PhaseConfig `json:"phase_config"`
+	Status       GameStatus  `json:"status"`
+	Winner       *Winner     `json:"winner,omitempty"`
+	LinesToWin   int         `json:"lines_to_win,omitempty"`  // Lines a team needs in normal rule, 0 means 1
+	PointsTarget int         `json:"points_target,omitempty"` // Score that wins in points rule, 0 means none
+	VerifyClaims bool        `json:"verify_claims,omitempty"` // Player marks are claims until a referee confirms them
+
+	// Shapes that win in normal rule, lines only if empty
+	WinPatterns   []string `json:"win_patterns,omitempty"`
+	CustomPattern [][]bool `json:"custom_pattern,omitempty"` // Cell mask of the "custom" pattern
 
-	// For phase rule tracking - per-row marks
-	RedRowMarks  [5]int `json:"red_row_marks"`  // Marks per row for red
-	BlueRowMarks [5]int `json:"blue_row_marks"` // Marks per row for blue
+	// Cells set up when the game starts: a void center cell counting for every team,
+	// and cells pre-marked for teams in handicap matches
+	FreeCenter bool       `json:"free_center,omitempty"`
+	Handicaps  []Handicap `json:"handicaps,omitempty"`
 
-	// Per-player row unlock tracking
-	RedUnlockedRow  int `json:"red_unlocked_row"`  // Highest row unlocked by red
-	BlueUnlockedRow int `json:"blue_unlocked_row"` // Highest row unlocked by blue
+	// Seed the board texts were generated from, nil for boards filled by hand
+	Seed *int64 `json:"seed,omitempty"`
+
+	// Teams taking part, in display order
+	Teams []Team `json:"teams"`
+
+	// Per-team row marks, unlock and settlement tracking (phase rule)
+	TeamStates map[TeamID]*TeamState `json:"team_states"`
 
 	// Bingo tracking (phase rule)
-	BingoAchiever PlayerColor `json:"bingo_achiever"` // Who achieved Bingo first
-	BingoLine     int         `json:"bingo_line"`     // Which line: 0-4 vertical, 5=diag\, 6=diag/
+	BingoAchiever TeamID `json:"bingo_achiever"` // Who achieved Bingo first
+	BingoLine     int    `json:"bingo_line"`     // Which line: 0..N-1 vertical, N=diag\, N+1=diag/
 
 	// Settlement tracking (phase rule)
-	RedSettled   bool        `json:"red_settled"`   // Whether red has settled
-	BlueSettled  bool        `json:"blue_settled"`  // Whether blue has settled
-	FirstSettler PlayerColor `json:"first_settler"` // Who settled first (for tie-breaking)
+	FirstSettler TeamID `json:"first_settler"` // Who settled first (for tie-breaking)
+
+	// Server clock
+	TimeLimit time.Duration `json:"time_limit,omitempty"` // 0 means no limit
+	Countdown time.Duration `json:"countdown,omitempty"`  // Delay between start and reveal, 0 starts at once
+	StartTime time.Time     `json:"start_time"`           // When the game started (revealed)
+	EndTime   time.Time     `json:"end_time"`             // When the game finished, zero while running
 }
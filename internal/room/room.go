@@ -6,42 +6,62 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"errors"
+	"slices"
 	"sync"
 	"time"
 )
 
 var (
-	ErrRoomNotFound     = errors.New("room not found")
-	ErrNotOwner         = errors.New("only room owner can do this")
-	ErrGameInProgress   = errors.New("game in progress")
-	ErrUserNotFound     = errors.New("user not found")
-	ErrPlayerAlreadySet = errors.New("player already set for this color")
+	ErrRoomNotFound   = errors.New("room not found")
+	ErrNotOwner       = errors.New("only room owner can do this")
+	ErrGameInProgress = errors.New("game in progress")
+	ErrUserNotFound   = errors.New("user not found")
+	ErrTeamFull       = errors.New("team is full")
+	ErrNothingToUndo  = errors.New("nothing to undo")
+	ErrNothingToRedo  = errors.New("nothing to redo")
 )
 
+// maxHistory caps the number of undoable actions kept per room
+const maxHistory = 200
+
+// DefaultEventRetention is how many games the event log of a new room keeps
+const DefaultEventRetention = 20
+
 // Room represents a game room
 type Room struct {
-	mu          sync.RWMutex
-	ID          string
-	Name        string
-	Password    string
-	OwnerID     string
-	Game        *game.Game
-	Users       map[string]*user.User
-	UserOrder   []string // Order of users for reference
-	StreamToken string   // Persistent SSE stream token for this room
-	emptyTimer  *time.Timer
+	mu             sync.RWMutex
+	ID             string
+	Name           string
+	Password       string
+	OwnerID        string
+	Game           *game.Game
+	Users          map[string]*user.User
+	UserOrder      []string      // Order of users for reference
+	StreamToken    string        // Persistent SSE stream token for this room
+	MaxTeamSize    int           // Max players per team, 0 means unlimited
+	EventRetention int           // Games whose events the log keeps, 0 keeps all
+	HiddenBoard    bool          // Cell texts only go to the owner and referee until the game starts
+	PublicIntents  bool          // Intent markers go to everyone, not just the player's team and the referee
+	GoalPool       []game.Goal   // Goals boards are generated from
+	Series         *game.Series  // Best-of-N series the room's games count towards, nil for none
+	Disputes       game.Disputes // Disputes of the current game, archived in the series when it is reset
+	Events         game.EventLog // Append-only log of game actions
+	undoStack      []*game.Game  // Game states before each undoable action
+	redoStack      []*game.Game  // Game states undone since the last action
+	emptyTimer     *time.Timer
 }
 
 // NewRoom creates a new room
 func NewRoom(id, name, password, ownerID string) *Room {
 	return &Room{
-		ID:        id,
-		Name:      name,
-		Password:  password,
-		OwnerID:   ownerID,
-		Game:      game.NewGame(game.RuleNormal),
-		Users:     make(map[string]*user.User),
-		UserOrder: []string{},
+		ID:             id,
+		Name:           name,
+		Password:       password,
+		OwnerID:        ownerID,
+		Game:           game.NewGame(game.RuleNormal),
+		Users:          make(map[string]*user.User),
+		UserOrder:      []string{},
+		EventRetention: DefaultEventRetention,
 	}
 }
 
@@ -62,7 +82,7 @@ func (r *Room) AddUser(u *user.User) error {
 
 	u.RoomID = r.ID
 	u.Role = user.RoleSpectator
-	u.PlayerColor = user.ColorNone
+	u.Team = ""
 	r.Users[u.ID] = u
 	r.UserOrder = append(r.UserOrder, u.ID)
 
@@ -83,7 +103,7 @@ func (r *Room) RemoveUser(userID string) {
 	if u, exists := r.Users[userID]; exists {
 		u.RoomID = ""
 		u.Role = user.RoleSpectator
-		u.PlayerColor = user.ColorNone
+		u.Team = ""
 		delete(r.Users, userID)
 
 		// Remove from order
@@ -105,7 +125,7 @@ func (r *Room) RemoveUser(userID string) {
 }
 
 // SetUserRole sets a user's role (owner can set anyone, users can set their own)
-func (r *Room) SetUserRole(callerID, targetUserID string, role user.UserRole, color user.PlayerColor) error {
+func (r *Room) SetUserRole(callerID, targetUserID string, role user.UserRole, team game.TeamID) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -119,25 +139,181 @@ func (r *Room) SetUserRole(callerID, targetUserID string, role user.UserRole, co
 		return ErrUserNotFound
 	}
 
-	// If setting as player, check if color is already taken
-	if role == user.RolePlayer && color != user.ColorNone {
-		for _, u := range r.Users {
-			if u.ID != targetUserID && u.PlayerColor == color {
-				return ErrPlayerAlreadySet
+	// If setting as player, check the team exists and has room for another member
+	if role == user.RolePlayer && team != game.TeamNone {
+		if !r.Game.HasTeam(team) {
+			return game.ErrUnknownTeam
+		}
+		if r.MaxTeamSize > 0 && targetUser.Team != string(team) {
+			members := 0
+			for _, u := range r.Users {
+				if u.Team == string(team) {
+					members++
+				}
+			}
+			if members >= r.MaxTeamSize {
+				return ErrTeamFull
 			}
 		}
 	}
 
 	targetUser.Role = role
 	if role == user.RolePlayer {
-		targetUser.PlayerColor = color
+		targetUser.Team = string(team)
 	} else {
-		targetUser.PlayerColor = user.ColorNone
+		targetUser.Team = ""
 	}
 
 	return nil
 }
 
+// SetMaxTeamSize sets the player limit per team (only owner can do this)
+// Players already on a team keep their place when the limit is lowered
+func (r *Room) SetMaxTeamSize(callerID string, size int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.OwnerID != callerID {
+		return ErrNotOwner
+	}
+
+	if size < 0 {
+		return errors.New("team size limit cannot be negative")
+	}
+
+	r.MaxTeamSize = size
+	return nil
+}
+
+// SetEventRetention sets how many games the event log keeps, 0 keeps all (only owner can do this)
+// Games of a running series are always kept; the log is trimmed when the next game starts
+func (r *Room) SetEventRetention(callerID string, games int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.OwnerID != callerID {
+		return ErrNotOwner
+	}
+
+	if games < 0 {
+		return errors.New("event retention cannot be negative")
+	}
+
+	r.EventRetention = games
+	return nil
+}
+
+// SetHiddenBoard sets whether the board is hidden from players and spectators
+// until the game starts (only owner can do this)
+func (r *Room) SetHiddenBoard(callerID string, hidden bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.OwnerID != callerID {
+		return ErrNotOwner
+	}
+
+	r.HiddenBoard = hidden
+	return nil
+}
+
+// SetPublicIntents sets whether intent markers are shown to everyone (only owner can do this)
+func (r *Room) SetPublicIntents(callerID string, public bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.OwnerID != callerID {
+		return ErrNotOwner
+	}
+
+	r.PublicIntents = public
+	return nil
+}
+
+// SetIntent shows or hides the player's "working on it" marker on a cell (only players can do this)
+// Intents are not undoable actions and are not logged
+func (r *Room) SetIntent(userID string, row, col int, active bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, exists := r.Users[userID]
+	if !exists {
+		return ErrUserNotFound
+	}
+	if u.Role != user.RolePlayer {
+		return errors.New("only players can show what they are working on")
+	}
+
+	mark := game.Mark{Team: game.TeamID(u.Team), PlayerID: u.ID, PlayerName: u.Name}
+	return r.Game.SetIntent(row, col, mark, active)
+}
+
+// SetGoalPool sets the goals boards are generated from, nil removes the pool (only owner can do this, only in waiting state)
+// The pool stays fixed while the game runs, so players can check the board against it
+func (r *Room) SetGoalPool(callerID string, goals []game.Goal) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.OwnerID != callerID {
+		return ErrNotOwner
+	}
+
+	if r.Game.Status != game.StatusWaiting {
+		return errors.New("can only change the goal pool in waiting state")
+	}
+
+	if len(goals) > 0 {
+		if err := game.ValidateGoalPool(goals); err != nil {
+			return err
+		}
+	}
+
+	r.GoalPool = goals
+	return nil
+}
+
+// GetGoalPool returns a copy of the room's goal pool
+// Owner and referee can always see it, everyone in the room once the game has started,
+// so the board can be regenerated from its seed and checked
+func (r *Room) GetGoalPool(callerID string) ([]game.Goal, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	u, ok := r.Users[callerID]
+	if !ok && callerID != r.OwnerID {
+		return nil, ErrUserNotFound
+	}
+	if callerID != r.OwnerID && u.Role != user.RoleReferee && !r.Game.Started() {
+		return nil, errors.New("only room owner or referee can see the goal pool before the game starts")
+	}
+	return slices.Clone(r.GoalPool), nil
+}
+
+// SeesHiddenBoard reports whether the user gets cell texts while they are hidden
+func (r *Room) SeesHiddenBoard(userID string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if userID == r.OwnerID {
+		return true
+	}
+	u, ok := r.Users[userID]
+	return ok && u.Role == user.RoleReferee
+}
+
+// BoardHidden reports whether the cell texts of g are withheld from everyone but the
+// owner and referee: during the start countdown, and before the start in rooms that
+// hide their board
+func BoardHidden(g *game.Game, hiddenBoard bool) bool {
+	switch g.Status {
+	case game.StatusCountdown:
+		return true
+	case game.StatusWaiting:
+		return hiddenBoard
+	}
+	return false
+}
+
 // SetPassword sets the room password (only owner can do this)
 func (r *Room) SetPassword(callerID, password string) error {
 	r.mu.Lock()
@@ -169,8 +345,22 @@ func (r *Room) HasPassword() bool {
 	return r.Password != ""
 }
 
-// SetGameRule sets the game rule (only owner can do this)
-func (r *Room) SetGameRule(callerID string, rule game.GameRule, config game.PhaseConfig) error {
+// RuleSettings are the game settings set together with the rule, nil fields keep their values
+type RuleSettings struct {
+	Rule          game.GameRule
+	Config        game.PhaseConfig
+	PointsTarget  *int            // Points rule: score that wins, 0 for none
+	WinPatterns   []string        // Normal rule: shapes that win
+	CustomPattern [][]bool        // Cell mask for the "custom" pattern
+	FreeCenter    *bool           // Center cell is a free space once the game starts
+	Handicaps     []game.Handicap // Empty clears them
+}
+
+// SetRule sets the game rule and its settings (only owner can do this, not while playing)
+// The settings are tried on a copy of the game, so nothing changes if any of them is refused;
+// start cells go last as they are checked against the new rule
+// Like ResetGame it clears a finished game, logging the reset
+func (r *Room) SetRule(callerID string, s RuleSettings) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -178,12 +368,202 @@ func (r *Room) SetGameRule(callerID string, rule game.GameRule, config game.Phas
 		return ErrNotOwner
 	}
 
-	if r.Game.Status == game.StatusPlaying {
+	if r.Game.Status == game.StatusPlaying || r.Game.Status == game.StatusCountdown {
 		return ErrGameInProgress
 	}
 
-	r.Game = game.NewGame(rule)
-	r.Game.PhaseConfig = config
+	g := r.Game.Clone()
+	if s.PointsTarget != nil {
+		if err := g.SetPointsTarget(*s.PointsTarget); err != nil {
+			return err
+		}
+	}
+	if s.WinPatterns != nil {
+		if err := g.SetWinPatterns(s.WinPatterns, s.CustomPattern); err != nil {
+			return err
+		}
+	}
+	if err := g.SetRule(s.Rule, s.Config); err != nil {
+		return err
+	}
+	if s.FreeCenter != nil {
+		if err := g.SetFreeCenter(*s.FreeCenter); err != nil {
+			return err
+		}
+	}
+	if s.Handicaps != nil {
+		if err := g.SetHandicaps(s.Handicaps); err != nil {
+			return err
+		}
+	}
+
+	started := r.Game.Started()
+	r.Game = g
+	r.Disputes.CloseGone(r.Game, time.Now())
+	r.Series.CloseGame(r.Disputes)
+	r.Disputes = nil
+	r.clearHistory()
+	if started {
+		r.logEvent(callerID, game.ActionReset, 0, 0, game.TeamNone)
+	}
+	return nil
+}
+
+// DisputeCell raises a dispute of another team's mark (only players can do this)
+func (r *Room) DisputeCell(userID string, row, col int, team game.TeamID, reason string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, exists := r.Users[userID]
+	if !exists {
+		return ErrUserNotFound
+	}
+	if u.Role != user.RolePlayer {
+		return errors.New("only players can dispute marks")
+	}
+
+	d, err := r.Disputes.Raise(r.Game, game.Dispute{
+		Time:       time.Now(),
+		Row:        row,
+		Col:        col,
+		Team:       team,
+		By:         game.TeamID(u.Team),
+		PlayerID:   u.ID,
+		PlayerName: u.Name,
+		Reason:     reason,
+	})
+	if err != nil {
+		return err
+	}
+	r.logEvent(userID, game.ActionDispute, d.Row, d.Col, d.Team)
+	return nil
+}
+
+// ResolveDispute upholds or reverts a disputed mark (only referee can do this)
+// Rulings are final: the undo history is dropped, so undo cannot bring back a reverted mark
+// or take away an upheld one
+func (r *Room) ResolveDispute(userID string, id int, status game.DisputeStatus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.checkReferee(userID, "resolve disputes"); err != nil {
+		return err
+	}
+	if status != game.DisputeUpheld && status != game.DisputeReverted {
+		return game.ErrInvalidDispute
+	}
+	d, err := r.Disputes.Find(id)
+	if err != nil {
+		return err
+	}
+	// Resolved on a copy, as the revert would otherwise close the dispute as gone
+	resolved := *d
+	if err := resolved.Resolve(status, r.Users[userID].Name, time.Now()); err != nil {
+		return err
+	}
+
+	if status == game.DisputeReverted {
+		err := r.applyAction(userID, game.ActionRevertMark, d.Row, d.Col, d.Team, func() error {
+			return r.Game.RevertMark(d.Row, d.Col, d.Team)
+		})
+		if err != nil {
+			return err
+		}
+	} else {
+		r.logEvent(userID, game.ActionUpholdMark, d.Row, d.Col, d.Team)
+	}
+	*d = resolved
+	r.clearHistory()
+	return nil
+}
+
+// SetVerifyClaims turns claim verification on or off (only owner can do this, not while playing)
+func (r *Room) SetVerifyClaims(callerID string, verify bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.OwnerID != callerID {
+		return ErrNotOwner
+	}
+
+	return r.Game.SetVerifyClaims(verify)
+}
+
+// SetTimeLimit sets the game's time limit, 0 for none (only owner can do this, not while playing)
+func (r *Room) SetTimeLimit(callerID string, limit time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.OwnerID != callerID {
+		return ErrNotOwner
+	}
+
+	return r.Game.SetTimeLimit(limit)
+}
+
+// SetLinesToWin sets how many lines a team needs in normal rule (only owner can do this, not while playing)
+func (r *Room) SetLinesToWin(callerID string, lines int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.OwnerID != callerID {
+		return ErrNotOwner
+	}
+
+	return r.Game.SetLinesToWin(lines)
+}
+
+// SetCountdown sets the countdown between starting and revealing the game (only owner can do this)
+func (r *Room) SetCountdown(callerID string, countdown time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.OwnerID != callerID {
+		return ErrNotOwner
+	}
+
+	return r.Game.SetCountdown(countdown)
+}
+
+// SetBoardSize sets the board size (only owner can do this, only in waiting state)
+func (r *Room) SetBoardSize(callerID string, size int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.OwnerID != callerID {
+		return ErrNotOwner
+	}
+
+	return r.Game.SetBoardSize(size)
+}
+
+// GetBoardSize returns the current board size
+func (r *Room) GetBoardSize() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.Game.Board.Size()
+}
+
+// SetTeams sets the teams taking part (only owner can do this, only in waiting state)
+// Players on a team that no longer exists become spectators
+func (r *Room) SetTeams(callerID string, teams []game.Team) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.OwnerID != callerID {
+		return ErrNotOwner
+	}
+
+	if err := r.Game.SetTeams(teams); err != nil {
+		return err
+	}
+
+	for _, u := range r.Users {
+		if u.Role == user.RolePlayer && !r.Game.HasTeam(game.TeamID(u.Team)) {
+			u.Role = user.RoleSpectator
+			u.Team = ""
+		}
+	}
 	return nil
 }
 
@@ -196,11 +576,31 @@ func (r *Room) StartGame(callerID string) error {
 		return ErrNotOwner
 	}
 
-	return r.Game.Start()
+	now := time.Now()
+	if err := r.Game.StartAt(now); err != nil {
+		return err
+	}
+	r.clearHistory()
+	r.logEvent(callerID, game.ActionStart, 0, 0, game.TeamNone).Game = r.Game.Clone()
+	if r.EventRetention > 0 {
+		r.Events.Prune(r.retainedGames())
+	}
+	r.updateClock(now)
+	return nil
+}
+
+// retainedGames returns how many games the event log keeps: the room's retention,
+// but at least the current game and those of the room's series (caller must hold r.mu)
+func (r *Room) retainedGames() int {
+	if r.Series == nil {
+		return r.EventRetention
+	}
+	return max(r.EventRetention, 1+len(r.Series.Games))
 }
 
 // MarkCell marks a cell in the game
-func (r *Room) MarkCell(userID string, row, col int, playerColor game.PlayerColor) error {
+// The mark records which user made it, so team members can be told apart
+func (r *Room) MarkCell(userID string, row, col int, team game.TeamID) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -209,25 +609,121 @@ func (r *Room) MarkCell(userID string, row, col int, playerColor game.PlayerColo
 		return ErrUserNotFound
 	}
 
+	mark := game.Mark{Team: team, PlayerID: u.ID, PlayerName: u.Name}
+	action := game.ActionMark
+
 	// Check permissions
 	switch u.Role {
 	case user.RoleReferee:
-		// In blackout and phase rules, referee can mark as second player (not force overwrite)
-		// In normal rule, referee still uses force overwrite
-		if r.Game.Rule == game.RuleBlackout || r.Game.Rule == game.RulePhase {
-			return r.Game.MarkCell(row, col, playerColor)
+		// The rule decides whether the referee overwrites the cell or marks like a team
+		if r.Game.RefereeOverrides() {
+			action = game.ActionForceMark
 		}
-		return r.Game.MarkCellForce(row, col, playerColor)
 	case user.RolePlayer:
-		// Can only mark own color
-		if playerColor != game.PlayerColor(u.PlayerColor) {
-			return errors.New("can only mark your own color")
+		// Can only mark for own team
+		if team != game.TeamID(u.Team) {
+			return errors.New("can only mark for your own team")
+		}
+		// With claim verification the mark waits for the referee
+		if r.Game.VerifyClaims {
+			action = game.ActionClaim
 		}
 	case user.RoleSpectator:
 		return errors.New("spectators cannot mark cells")
 	}
 
-	return r.Game.MarkCell(row, col, playerColor)
+	return r.applyAction(userID, action, row, col, team, func() error {
+		// Teams with a start delay wait it out, the referee is not held back
+		if u.Role == user.RolePlayer {
+			if err := r.Game.CheckStartDelay(team, time.Now()); err != nil {
+				return err
+			}
+		}
+		switch action {
+		case game.ActionForceMark:
+			return r.Game.MarkCellForceBy(row, col, mark)
+		case game.ActionClaim:
+			return r.Game.ClaimCell(row, col, mark)
+		}
+		return r.Game.MarkCellBy(row, col, mark)
+	})
+}
+
+// AddProgress raises or lowers a team's counter on a cell by one
+// Players count for their own team, the referee for any team
+func (r *Room) AddProgress(userID string, row, col int, team game.TeamID, up bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, exists := r.Users[userID]
+	if !exists {
+		return ErrUserNotFound
+	}
+
+	switch u.Role {
+	case user.RolePlayer:
+		if team != game.TeamID(u.Team) {
+			return errors.New("can only count for your own team")
+		}
+	case user.RoleSpectator:
+		return errors.New("spectators cannot count progress")
+	}
+
+	mark := game.Mark{Team: team, PlayerID: u.ID, PlayerName: u.Name}
+	action, delta := game.ActionProgressUp, 1
+	if !up {
+		action, delta = game.ActionProgressDown, -1
+	}
+	return r.applyAction(userID, action, row, col, team, func() error {
+		if u.Role == user.RolePlayer {
+			if err := r.Game.CheckStartDelay(team, time.Now()); err != nil {
+				return err
+			}
+		}
+		return r.Game.AddProgress(row, col, mark, delta)
+	})
+}
+
+// ConfirmClaim turns a team's pending claim into a mark (only referee can do this)
+func (r *Room) ConfirmClaim(userID string, row, col int, team game.TeamID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.checkReferee(userID, "confirm claims"); err != nil {
+		return err
+	}
+
+	return r.applyAction(userID, game.ActionConfirmClaim, row, col, team, func() error {
+		return r.Game.ConfirmClaim(row, col, team)
+	})
+}
+
+// RejectClaim drops a team's pending claim (only referee can do this)
+func (r *Room) RejectClaim(userID string, row, col int, team game.TeamID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.checkReferee(userID, "reject claims"); err != nil {
+		return err
+	}
+
+	return r.applyAction(userID, game.ActionRejectClaim, row, col, team, func() error {
+		return r.Game.RejectClaim(row, col, team)
+	})
+}
+
+// SetCellLock locks, voids or reopens a cell (only referee can do this)
+func (r *Room) SetCellLock(userID string, row, col int, lock game.CellLock) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.checkReferee(userID, "lock cells"); err != nil {
+		return err
+	}
+
+	return r.applyAction(userID, lock.Action(), row, col, game.TeamNone, func() error {
+		return r.Game.SetCellLock(row, col, lock)
+	})
 }
 
 // UnmarkCell removes a mark from a cell (only referee can do this)
@@ -244,12 +740,14 @@ func (r *Room) UnmarkCell(userID string, row, col int) error {
 		return errors.New("only referee can unmark cells")
 	}
 
-	return r.Game.UnmarkCell(row, col)
+	return r.applyAction(userID, game.ActionUnmark, row, col, game.TeamNone, func() error {
+		return r.Game.UnmarkCell(row, col)
+	})
 }
 
-// ClearCellMark clears a specific color mark from a cell
-// For blackout and phase rules where both colors can mark the same cell
-func (r *Room) ClearCellMark(userID string, row, col int, playerColor game.PlayerColor) error {
+// ClearCellMark clears a specific team's mark from a cell
+// For blackout and phase rules where several teams can mark the same cell
+func (r *Room) ClearCellMark(userID string, row, col int, team game.TeamID) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -263,14 +761,16 @@ func (r *Room) ClearCellMark(userID string, row, col int, playerColor game.Playe
 		return errors.New("spectators cannot clear marks")
 	}
 
-	// Players can only clear their own color
+	// Players can only clear their own team's mark
 	if u.Role == user.RolePlayer {
-		if playerColor != game.PlayerColor(u.PlayerColor) {
-			return errors.New("can only clear your own color")
+		if team != game.TeamID(u.Team) {
+			return errors.New("can only clear your own team's mark")
 		}
 	}
 
-	return r.Game.ClearCellMark(row, col, playerColor)
+	return r.applyAction(userID, game.ActionClearMark, row, col, team, func() error {
+		return r.Game.ClearCellMark(row, col, team)
+	})
 }
 
 // ResetGame resets the game board (only owner can do this)
@@ -283,6 +783,34 @@ func (r *Room) ResetGame(callerID string) error {
 	}
 
 	r.Game.Reset()
+	r.Disputes.CloseGone(r.Game, time.Now())
+	r.Series.CloseGame(r.Disputes)
+	r.Disputes = nil
+	r.clearHistory()
+	r.logEvent(callerID, game.ActionReset, 0, 0, game.TeamNone)
+	return nil
+}
+
+// SetSeries starts a best-of-N series, 0 ends the current one (only owner can do this)
+// Results recorded so far are dropped, a game that has just finished counts as the first
+func (r *Room) SetSeries(callerID string, bestOf int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.OwnerID != callerID {
+		return ErrNotOwner
+	}
+
+	if bestOf == 0 {
+		r.Series = nil
+		return nil
+	}
+	series, err := game.NewSeries(bestOf)
+	if err != nil {
+		return err
+	}
+	r.Series = series
+	r.Series.SyncGame(r.Game)
 	return nil
 }
 
@@ -299,6 +827,14 @@ func (r *Room) SetCellText(callerID string, row, col int, text string) error {
 		return errors.New("can only set cell text in waiting state")
 	}
 
+	if r.Game.Board.InBounds(row, col) {
+		texts := r.Game.Board.Texts()
+		texts[row*r.Game.Board.Size()+col] = text
+		if err := r.checkBoardTexts(texts); err != nil {
+			return err
+		}
+	}
+
 	return r.Game.SetCellText(row, col, text)
 }
 
@@ -315,12 +851,114 @@ func (r *Room) SetAllCellTexts(callerID string, texts []string) error {
 		return errors.New("can only set cell text in waiting state")
 	}
 
+	if err := r.checkBoardTexts(texts); err != nil {
+		return err
+	}
+
 	return r.Game.SetAllCellTexts(texts)
 }
 
-// Settle triggers settlement for a player in phase rule
+// SetCellPoints sets the point value of a cell (only owner can do this, only in waiting state)
+func (r *Room) SetCellPoints(callerID string, row, col, points int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.OwnerID != callerID {
+		return ErrNotOwner
+	}
+
+	if r.Game.Status != game.StatusWaiting {
+		return errors.New("can only set cell points in waiting state")
+	}
+
+	return r.Game.SetCellPoints(row, col, points)
+}
+
+// SetAllCellPoints sets all cell point values (only owner can do this, only in waiting state)
+func (r *Room) SetAllCellPoints(callerID string, points []int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.OwnerID != callerID {
+		return ErrNotOwner
+	}
+
+	if r.Game.Status != game.StatusWaiting {
+		return errors.New("can only set cell points in waiting state")
+	}
+
+	return r.Game.SetAllCellPoints(points)
+}
+
+// SetCellTarget sets the target count of a cell (only owner can do this, only in waiting state)
+func (r *Room) SetCellTarget(callerID string, row, col, target int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.OwnerID != callerID {
+		return ErrNotOwner
+	}
+
+	if r.Game.Status != game.StatusWaiting {
+		return errors.New("can only set cell targets in waiting state")
+	}
+
+	return r.Game.SetCellTarget(row, col, target)
+}
+
+// SetAllCellTargets sets all cell target counts (only owner can do this, only in waiting state)
+func (r *Room) SetAllCellTargets(callerID string, targets []int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.OwnerID != callerID {
+		return ErrNotOwner
+	}
+
+	if r.Game.Status != game.StatusWaiting {
+		return errors.New("can only set cell targets in waiting state")
+	}
+
+	return r.Game.SetAllCellTargets(targets)
+}
+
+// checkBoardTexts checks board texts against the exclusion groups and
+// anti-synergy tags of the goal pool, texts not in the pool are free
+func (r *Room) checkBoardTexts(texts []string) error {
+	if len(r.GoalPool) == 0 {
+		return nil
+	}
+	return game.CheckBoard(r.GoalPool, r.Game.Board.Size(), texts)
+}
+
+// GenerateBoard fills the board from the goal pool (only owner can do this, only in waiting state)
+// A nil seed picks a random one, the seed used is stored with the game
+func (r *Room) GenerateBoard(callerID string, seed *int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.OwnerID != callerID {
+		return ErrNotOwner
+	}
+
+	if r.Game.Status != game.StatusWaiting {
+		return errors.New("can only generate the board in waiting state")
+	}
+
+	if len(r.GoalPool) == 0 {
+		return errors.New("room has no goal pool")
+	}
+
+	s := game.RandomSeed()
+	if seed != nil {
+		s = *seed
+	}
+	return r.Game.GenerateBoard(r.GoalPool, s)
+}
+
+// Settle triggers settlement for a team in rules that end by settlement
 // Player can settle for themselves, or referee can settle for players
-func (r *Room) Settle(callerID string, playerColor game.PlayerColor) error {
+func (r *Room) Settle(callerID string, team game.TeamID) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -329,20 +967,187 @@ func (r *Room) Settle(callerID string, playerColor game.PlayerColor) error {
 		return ErrUserNotFound
 	}
 
-	// Check permissions: player can settle themselves, referee can settle anyone
+	// Check permissions: player can settle their team, referee can settle any team
 	switch u.Role {
 	case user.RoleReferee:
-		// Can settle for any player
+		// Can settle for any team
 	case user.RolePlayer:
-		// Can only settle for themselves
-		if game.PlayerColor(u.PlayerColor) != playerColor {
+		// Can only settle for their own team
+		if game.TeamID(u.Team) != team {
 			return errors.New("can only settle for yourself")
 		}
 	case user.RoleSpectator:
 		return errors.New("spectators cannot settle")
 	}
 
-	return r.Game.Settle(playerColor)
+	return r.applyAction(callerID, game.ActionSettle, 0, 0, team, func() error {
+		return r.Game.Settle(team)
+	})
+}
+
+// Undo restores the game state from before the last action (only referee can do this)
+func (r *Room) Undo(callerID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.checkReferee(callerID, "undo"); err != nil {
+		return err
+	}
+
+	if len(r.undoStack) == 0 {
+		return ErrNothingToUndo
+	}
+
+	last := len(r.undoStack) - 1
+	r.undoStack[last].KeepIntents(r.Game)
+	r.redoStack = append(r.redoStack, r.Game)
+	r.Game = r.undoStack[last]
+	r.undoStack = r.undoStack[:last]
+	r.logEvent(callerID, game.ActionUndo, 0, 0, game.TeamNone)
+	r.updateClock(time.Now())
+	r.Series.SyncGame(r.Game)
+	r.Disputes.CloseGone(r.Game, time.Now())
+	return nil
+}
+
+// Redo reapplies the last undone action (only referee can do this)
+func (r *Room) Redo(callerID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.checkReferee(callerID, "redo"); err != nil {
+		return err
+	}
+
+	if len(r.redoStack) == 0 {
+		return ErrNothingToRedo
+	}
+
+	last := len(r.redoStack) - 1
+	r.redoStack[last].KeepIntents(r.Game)
+	r.undoStack = append(r.undoStack, r.Game)
+	r.Game = r.redoStack[last]
+	r.redoStack = r.redoStack[:last]
+	r.logEvent(callerID, game.ActionRedo, 0, 0, game.TeamNone)
+	r.updateClock(time.Now())
+	r.Series.SyncGame(r.Game)
+	r.Disputes.CloseGone(r.Game, time.Now())
+	return nil
+}
+
+// checkReferee returns an error unless the user is the room's referee (caller must hold r.mu)
+func (r *Room) checkReferee(userID, what string) error {
+	u, exists := r.Users[userID]
+	if !exists {
+		return ErrUserNotFound
+	}
+	if u.Role != user.RoleReferee {
+		return errors.New("only referee can " + what)
+	}
+	return nil
+}
+
+// applyAction runs an undoable game action, keeping the previous state and logging it
+// (caller must hold r.mu)
+// The game clock is applied first, so actions after the time limit fail against the finished game
+func (r *Room) applyAction(actorID string, action game.EventAction, row, col int, team game.TeamID, apply func() error) error {
+	now := time.Now()
+	r.updateClock(now)
+
+	before := r.Game.Clone()
+	if err := apply(); err != nil {
+		return err
+	}
+
+	r.undoStack = append(r.undoStack, before)
+	if len(r.undoStack) > maxHistory {
+		r.undoStack = slices.Delete(r.undoStack, 0, 1)
+	}
+	r.redoStack = nil
+	r.logEvent(actorID, action, row, col, team)
+	r.updateClock(now)
+	r.Series.SyncGame(r.Game)
+	r.Disputes.CloseGone(r.Game, now)
+	return nil
+}
+
+// updateClock applies the game clock, logging a timeout when the time limit ran out
+// and recording the result in the series (caller must hold r.mu)
+func (r *Room) updateClock(now time.Time) bool {
+	if !r.Game.UpdateClock(now) {
+		return false
+	}
+	r.logEvent("", game.ActionTimeout, 0, 0, r.Game.Winner.Winner)
+	r.Series.SyncGame(r.Game)
+	return true
+}
+
+// UpdateClock ends the game if its time limit has run out, returns true if it did
+func (r *Room) UpdateClock(now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.updateClock(now)
+}
+
+// NextClockEvent returns when the game clock next changes the game:
+// the reveal during a countdown, the time limit while playing
+func (r *Room) NextClockEvent() (time.Time, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	switch r.Game.Status {
+	case game.StatusCountdown:
+		return r.Game.RevealTime()
+	case game.StatusPlaying:
+		return r.Game.Deadline()
+	}
+	return time.Time{}, false
+}
+
+// clearHistory drops all undo and redo states (caller must hold r.mu)
+func (r *Room) clearHistory() {
+	r.undoStack = nil
+	r.redoStack = nil
+}
+
+// logEvent appends a game action to the event log and returns the stored entry
+// (caller must hold r.mu)
+func (r *Room) logEvent(actorID string, action game.EventAction, row, col int, team game.TeamID) *game.Event {
+	actorName := ""
+	if u, ok := r.Users[actorID]; ok {
+		actorName = u.Name
+	}
+	r.Events.Append(game.Event{
+		Time:      time.Now(),
+		ActorID:   actorID,
+		ActorName: actorName,
+		Action:    action,
+		Row:       row,
+		Col:       col,
+		Team:      team,
+	})
+	return &r.Events[len(r.Events)-1]
+}
+
+// ReplayToSeq rebuilds the game state right after the logged event seq
+func (r *Room) ReplayToSeq(seq int) (*game.Game, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return game.ReplayToSeq(r.Events, seq)
+}
+
+// ReplayToTime rebuilds the game state as it was at time t
+func (r *Room) ReplayToTime(t time.Time) (*game.Game, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return game.ReplayToTime(r.Events, t)
+}
+
+// GetEvents returns the logged events after the given sequence number
+func (r *Room) GetEvents(since int) []game.Event {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.Events.Since(since)
 }
 
 // GetState returns the current room state
@@ -353,48 +1158,110 @@ func (r *Room) GetState() *RoomState {
 	users := make([]UserInfo, 0, len(r.Users))
 	for _, u := range r.Users {
 		users = append(users, UserInfo{
-			ID:          u.ID,
-			Name:        u.Name,
-			Role:        u.Role.String(),
-			PlayerColor: u.PlayerColor.String(),
+			ID:   u.ID,
+			Name: u.Name,
+			Role: u.Role.String(),
+			Team: u.Team,
 		})
 	}
 
 	return &RoomState{
-		ID:          r.ID,
-		Name:        r.Name,
-		OwnerID:     r.OwnerID,
-		HasPassword: r.Password != "",
-		Game:        r.Game,
-		Users:       users,
+		ID:             r.ID,
+		Name:           r.Name,
+		OwnerID:        r.OwnerID,
+		HasPassword:    r.Password != "",
+		MaxTeamSize:    r.MaxTeamSize,
+		EventRetention: r.EventRetention,
+		HiddenBoard:    r.HiddenBoard,
+		PublicIntents:  r.PublicIntents,
+		GoalPoolSize:   len(r.GoalPool),
+		Series:         r.Series.Clone(),
+		Disputes:       slices.Clone(r.Disputes),
+		DisputeQueue:   r.Disputes.Open(),
+		CanUndo:        len(r.undoStack) > 0,
+		CanRedo:        len(r.redoStack) > 0,
+		Game:           r.Game,
+		Users:          users,
 	}
 }
 
 // UserInfo represents user info for room state
 type UserInfo struct {
-	ID          string `json:"id"`
-	Name        string `json:"name"`
-	Role        string `json:"role"`
-	PlayerColor string `json:"player_color"`
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Role string `json:"role"`
+	Team string `json:"team"`
 }
 
 // RoomState represents the full room state
 type RoomState struct {
-	ID          string     `json:"id"`
-	Name        string     `json:"name"`
-	OwnerID     string     `json:"owner_id"`
-	HasPassword bool       `json:"has_password"`
-	Game        *game.Game `json:"game"`
-	Users       []UserInfo `json:"users"`
+	ID             string         `json:"id"`
+	Name           string         `json:"name"`
+	OwnerID        string         `json:"owner_id"`
+	HasPassword    bool           `json:"has_password"`
+	MaxTeamSize    int            `json:"max_team_size"`
+	EventRetention int            `json:"event_retention"`
+	HiddenBoard    bool           `json:"hidden_board"`
+	PublicIntents  bool           `json:"public_intents"`
+	GoalPoolSize   int            `json:"goal_pool_size"`
+	Series         *game.Series   `json:"series,omitempty"` // Including the current game once it has finished
+	Disputes       []game.Dispute `json:"disputes,omitempty"`
+	DisputeQueue   []game.Dispute `json:"dispute_queue,omitempty"` // Open disputes, oldest first
+	CanUndo        bool           `json:"can_undo"`
+	CanRedo        bool           `json:"can_redo"`
+	Game           *game.Game     `json:"game"`
+	Users          []UserInfo     `json:"users"`
+}
+
+// BoardVisibleTo reports whether the user gets the real cell texts of this state
+// An empty userID stands for overlay viewers, who never see a hidden board
+func (s *RoomState) BoardVisibleTo(userID string) bool {
+	if !BoardHidden(s.Game, s.HiddenBoard) {
+		return true
+	}
+	if userID == "" {
+		return false
+	}
+	if userID == s.OwnerID {
+		return true
+	}
+	for _, u := range s.Users {
+		if u.ID == userID {
+			return u.Role == user.RoleReferee.String()
+		}
+	}
+	return false
+}
+
+// SeesIntent reports whether the user gets intent markers of the given team
+// Players see their own team's, the referee sees all, and everyone sees all once they are public
+func (s *RoomState) SeesIntent(userID string, team game.TeamID) bool {
+	if s.PublicIntents {
+		return true
+	}
+	for _, u := range s.Users {
+		if u.ID == userID {
+			return u.Role == user.RoleReferee.String() || (u.Role == user.RolePlayer.String() && game.TeamID(u.Team) == team)
+		}
+	}
+	return false
 }
 
 // PersistData represents data for persistence (no users)
 type PersistData struct {
-	ID          string     `json:"id"`
-	Name        string     `json:"name"`
-	Password    string     `json:"password"`
-	Game        *game.Game `json:"game"`
-	StreamToken string     `json:"stream_token,omitempty"`
+	ID             string         `json:"id"`
+	Name           string         `json:"name"`
+	Password       string         `json:"password"`
+	Game           *game.Game     `json:"game"`
+	StreamToken    string         `json:"stream_token,omitempty"`
+	MaxTeamSize    int            `json:"max_team_size,omitempty"`
+	EventRetention int            `json:"event_retention,omitempty"` // 0 for rooms saved before the setting existed, which keep all
+	HiddenBoard    bool           `json:"hidden_board,omitempty"`
+	PublicIntents  bool           `json:"public_intents,omitempty"`
+	GoalPool       []game.Goal    `json:"goal_pool,omitempty"`
+	Series         *game.Series   `json:"series,omitempty"`
+	Disputes       []game.Dispute `json:"disputes,omitempty"`
+	Events         []game.Event   `json:"events,omitempty"`
 }
 
 // GetPersistData returns data for persistence
@@ -402,11 +1269,19 @@ func (r *Room) GetPersistData() *PersistData {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 	return &PersistData{
-		ID:          r.ID,
-		Name:        r.Name,
-		Password:    r.Password,
-		Game:        r.Game,
-		StreamToken: r.StreamToken,
+		ID:             r.ID,
+		Name:           r.Name,
+		Password:       r.Password,
+		Game:           r.Game,
+		StreamToken:    r.StreamToken,
+		MaxTeamSize:    r.MaxTeamSize,
+		EventRetention: r.EventRetention,
+		HiddenBoard:    r.HiddenBoard,
+		PublicIntents:  r.PublicIntents,
+		GoalPool:       r.GoalPool,
+		Series:         r.Series.Clone(),
+		Disputes:       slices.Clone(r.Disputes),
+		Events:         r.Events.Since(0),
 	}
 }
 
@@ -507,16 +1382,24 @@ func generateRoomID() string {
 }
 
 // RestoreRoom creates a room from persisted data
-func RestoreRoom(id, name, password, streamToken string, g *game.Game) *Room {
+func RestoreRoom(data *PersistData) *Room {
 	return &Room{
-		ID:          id,
-		Name:        name,
-		Password:    password,
-		OwnerID:     "",
-		Game:        g,
-		Users:       make(map[string]*user.User),
-		UserOrder:   []string{},
-		StreamToken: streamToken,
+		ID:             data.ID,
+		Name:           data.Name,
+		Password:       data.Password,
+		OwnerID:        "",
+		Game:           data.Game,
+		Users:          make(map[string]*user.User),
+		UserOrder:      []string{},
+		StreamToken:    data.StreamToken,
+		MaxTeamSize:    data.MaxTeamSize,
+		EventRetention: data.EventRetention,
+		HiddenBoard:    data.HiddenBoard,
+		PublicIntents:  data.PublicIntents,
+		GoalPool:       data.GoalPool,
+		Series:         data.Series,
+		Disputes:       data.Disputes,
+		Events:         data.Events,
 	}
 }
 
@@ -559,8 +1442,8 @@ func (m *Manager) ScheduleDeleteIfEmpty(id string) bool {
 		return false
 	}
 
-	// Game finished - delete immediately
-	if r.Game.Status == game.StatusFinished {
+	// Game finished and no series to go on with - delete immediately
+	if r.Game.Status == game.StatusFinished && !r.Series.Running() {
 		delete(m.rooms, id)
 		if m.onDelete != nil {
 			m.onDelete(id, true)
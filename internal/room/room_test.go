@@ -0,0 +1,421 @@
+package room
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"bingosync/internal/game"
+	"bingosync/internal/user"
+)
+
+// newTestRoom returns a room with its owner in it
+func newTestRoom(t *testing.T) (*Room, *user.User) {
+	t.Helper()
+	owner := user.NewUser("owner")
+	r := NewRoom("room1", "test", "", owner.ID)
+	if err := r.AddUser(owner); err != nil {
+		t.Fatalf("AddUser failed: %v", err)
+	}
+	return r, owner
+}
+
+// addPlayer adds a user playing for the team
+func addPlayer(t *testing.T, r *Room, name string, team game.TeamID) *user.User {
+	t.Helper()
+	u := user.NewUser(name)
+	r.AddUser(u)
+	if err := r.SetUserRole(u.ID, u.ID, user.RolePlayer, team); err != nil {
+		t.Fatalf("SetUserRole failed: %v", err)
+	}
+	return u
+}
+
+// addReferee adds a user refereeing the room
+func addReferee(t *testing.T, r *Room) *user.User {
+	t.Helper()
+	u := user.NewUser("referee")
+	r.AddUser(u)
+	if err := r.SetUserRole(r.OwnerID, u.ID, user.RoleReferee, game.TeamNone); err != nil {
+		t.Fatalf("SetUserRole failed: %v", err)
+	}
+	return u
+}
+
+// countStarts returns how many game starts the room's event log holds
+func countStarts(r *Room) int {
+	n := 0
+	for _, e := range r.Events {
+		if e.Action == game.ActionStart {
+			n++
+		}
+	}
+	return n
+}
+
+func TestEventRetention(t *testing.T) {
+	r, owner := newTestRoom(t)
+	if r.EventRetention != DefaultEventRetention {
+		t.Errorf("New room should keep %d games, got %d", DefaultEventRetention, r.EventRetention)
+	}
+	if err := r.SetEventRetention("someone", 1); err != ErrNotOwner {
+		t.Errorf("Only the owner should set the retention, got: %v", err)
+	}
+	if err := r.SetEventRetention(owner.ID, -1); err == nil {
+		t.Error("Negative retention should fail")
+	}
+
+	r.SetEventRetention(owner.ID, 2)
+	for i := 0; i < 4; i++ {
+		r.StartGame(owner.ID)
+		r.ResetGame(owner.ID)
+	}
+	r.StartGame(owner.ID)
+	if n := countStarts(r); n != 2 {
+		t.Errorf("Log should keep the last 2 games, got %d starts", n)
+	}
+	if r.Events[0].Seq != 7 || r.Events.LastSeq() != 9 {
+		t.Errorf("Log should start at a game start and keep its seqs, got %+v", r.Events[0])
+	}
+
+	// Turning retention off keeps everything from now on
+	r.SetEventRetention(owner.ID, 0)
+	for i := 0; i < 3; i++ {
+		r.ResetGame(owner.ID)
+		r.StartGame(owner.ID)
+	}
+	if n := countStarts(r); n != 5 {
+		t.Errorf("Log should keep every game without retention, got %d starts", n)
+	}
+}
+
+func TestEventRetentionSeries(t *testing.T) {
+	r, owner := newTestRoom(t)
+	red := addPlayer(t, r, "red", game.TeamRed)
+	r.SetEventRetention(owner.ID, 1)
+	r.SetSeries(owner.ID, 5)
+
+	// Finished games of a running series are kept beyond the retention
+	for i := 0; i < 3; i++ {
+		r.StartGame(owner.ID)
+		for col := 0; col < r.Game.Board.Size(); col++ {
+			r.MarkCell(red.ID, 0, col, game.TeamRed)
+		}
+		r.ResetGame(owner.ID)
+	}
+	r.StartGame(owner.ID)
+	if len(r.Series.Games) != 3 {
+		t.Fatalf("Series should have 3 games, got %d", len(r.Series.Games))
+	}
+	if n := countStarts(r); n != 4 {
+		t.Errorf("Log should keep the series games and the current one, got %d starts", n)
+	}
+}
+
+func TestHiddenBoardVisibility(t *testing.T) {
+	r, owner := newTestRoom(t)
+	red := addPlayer(t, r, "red", game.TeamRed)
+	referee := addReferee(t, r)
+	spectator := user.NewUser("spectator")
+	r.AddUser(spectator)
+
+	r.SetHiddenBoard(owner.ID, true)
+	want := map[string]bool{owner.ID: true, referee.ID: true, red.ID: false, spectator.ID: false, "": false}
+	state := r.GetState()
+	for id, visible := range want {
+		if state.BoardVisibleTo(id) != visible {
+			t.Errorf("Waiting hidden board: %q should see it %v", id, visible)
+		}
+		if id != "" && r.SeesHiddenBoard(id) != visible {
+			t.Errorf("SeesHiddenBoard(%q) should be %v", id, visible)
+		}
+	}
+
+	// The countdown hides the board from the same users even without the setting
+	r.SetHiddenBoard(owner.ID, false)
+	r.SetCountdown(owner.ID, 10*time.Second)
+	r.StartGame(owner.ID)
+	state = r.GetState()
+	for id, visible := range want {
+		if state.BoardVisibleTo(id) != visible {
+			t.Errorf("Countdown: %q should see the board %v", id, visible)
+		}
+	}
+
+	// Once revealed everyone sees the board
+	r.UpdateClock(r.Game.StartTime)
+	state = r.GetState()
+	for id := range want {
+		if !state.BoardVisibleTo(id) {
+			t.Errorf("Revealed board should be visible to %q", id)
+		}
+	}
+}
+
+func TestIntentVisibility(t *testing.T) {
+	r, owner := newTestRoom(t)
+	red := addPlayer(t, r, "red", game.TeamRed)
+	blue := addPlayer(t, r, "blue", game.TeamBlue)
+	referee := addReferee(t, r)
+
+	state := r.GetState()
+	if !state.SeesIntent(red.ID, game.TeamRed) || state.SeesIntent(blue.ID, game.TeamRed) || !state.SeesIntent(referee.ID, game.TeamRed) {
+		t.Error("Red intents should go to red players and the referee only")
+	}
+	if state.SeesIntent(owner.ID, game.TeamRed) {
+		t.Error("Owner without a role should not see intents")
+	}
+
+	r.SetPublicIntents(owner.ID, true)
+	if !r.GetState().SeesIntent(blue.ID, game.TeamRed) {
+		t.Error("Public intents should go to everyone")
+	}
+}
+
+func TestUndoRedo(t *testing.T) {
+	r, owner := newTestRoom(t)
+	red := addPlayer(t, r, "red", game.TeamRed)
+	referee := addReferee(t, r)
+	r.StartGame(owner.ID)
+
+	if err := r.Undo(red.ID); err == nil {
+		t.Error("Only the referee should undo")
+	}
+	if err := r.Undo(referee.ID); err != ErrNothingToUndo {
+		t.Errorf("Start should leave nothing to undo, got: %v", err)
+	}
+
+	r.MarkCell(red.ID, 0, 0, game.TeamRed)
+	if err := r.Undo(referee.ID); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if r.Game.Board.Cells[0][0].MarkedBy() != game.TeamNone {
+		t.Fatal("Undo should take the mark back")
+	}
+	if err := r.Redo(referee.ID); err != nil {
+		t.Fatalf("Redo failed: %v", err)
+	}
+	if r.Game.Board.Cells[0][0].MarkedBy() != game.TeamRed {
+		t.Fatal("Redo should put the mark back")
+	}
+
+	// A new action drops what was undone
+	r.Undo(referee.ID)
+	r.MarkCell(red.ID, 1, 1, game.TeamRed)
+	if err := r.Redo(referee.ID); err != ErrNothingToRedo {
+		t.Errorf("New action should discard the redo states, got: %v", err)
+	}
+	if state := r.GetState(); !state.CanUndo || state.CanRedo {
+		t.Errorf("State should allow undo only, got undo %v redo %v", state.CanUndo, state.CanRedo)
+	}
+
+	// Starting the next game clears the history
+	r.ResetGame(owner.ID)
+	r.StartGame(owner.ID)
+	if err := r.Undo(referee.ID); err != ErrNothingToUndo {
+		t.Errorf("Start should clear the history, got: %v", err)
+	}
+}
+
+func TestUndoHistoryCap(t *testing.T) {
+	r, owner := newTestRoom(t)
+	red := addPlayer(t, r, "red", game.TeamRed)
+	referee := addReferee(t, r)
+	r.StartGame(owner.ID)
+
+	// Marking and clearing the same cell, one more action than the history holds
+	for i := 0; i <= maxHistory; i++ {
+		if i%2 == 0 {
+			r.MarkCell(red.ID, 0, 0, game.TeamRed)
+		} else {
+			r.ClearCellMark(red.ID, 0, 0, game.TeamRed)
+		}
+	}
+	undone := 0
+	for r.Undo(referee.ID) == nil {
+		undone++
+	}
+	if undone != maxHistory {
+		t.Errorf("History should hold %d actions, undid %d", maxHistory, undone)
+	}
+	if r.Game.Board.Cells[0][0].MarkedBy() != game.TeamRed {
+		t.Error("Oldest action should have been dropped, leaving the first mark")
+	}
+}
+
+func TestSetRuleAppliesNothingOnError(t *testing.T) {
+	r, owner := newTestRoom(t)
+	r.SetCellText(owner.ID, 0, 0, "goal")
+	before := r.Game.Clone()
+
+	free := true
+	config := game.DefaultPhaseConfig(5)
+	config.BingoBonus = 7
+	refused := []RuleSettings{
+		// Invalid handicap after a valid rule, patterns and free center
+		{Rule: game.RulePhase, Config: config, WinPatterns: []string{game.PatternX}, FreeCenter: &free,
+			Handicaps: []game.Handicap{{Team: "green", ScoreOffset: 1}}},
+		// Invalid pattern
+		{Rule: game.RuleNormal, Config: config, WinPatterns: []string{"star"}},
+	}
+	for i, s := range refused {
+		if err := r.SetRule(owner.ID, s); err == nil {
+			t.Errorf("Settings %d should be refused", i)
+		}
+		if r.Game.Rule != before.Rule || !reflect.DeepEqual(r.Game.PhaseConfig, before.PhaseConfig) ||
+			r.Game.WinPatterns != nil || r.Game.FreeCenter || r.Game.Handicaps != nil {
+			t.Errorf("Refused settings %d should change nothing, got rule %q patterns %v", i, r.Game.Rule, r.Game.WinPatterns)
+		}
+		if !reflect.DeepEqual(r.Game.Board, before.Board) {
+			t.Errorf("Refused settings %d should keep the board", i)
+		}
+	}
+
+	s := refused[0]
+	s.Handicaps = []game.Handicap{{Team: game.TeamBlue, ScoreOffset: 1}}
+	if err := r.SetRule(owner.ID, s); err != nil {
+		t.Fatalf("SetRule failed: %v", err)
+	}
+	if r.Game.Rule != game.RulePhase || r.Game.PhaseConfig.BingoBonus != 7 || !r.Game.FreeCenter || len(r.Game.Handicaps) != 1 {
+		t.Errorf("Valid settings should all be applied, got: %+v", r.Game)
+	}
+}
+
+func TestRefereeMarkFollowsRule(t *testing.T) {
+	r, owner := newTestRoom(t)
+	referee := addReferee(t, r)
+	last := func() game.EventAction { return r.Events[len(r.Events)-1].Action }
+
+	r.StartGame(owner.ID)
+	r.MarkCell(referee.ID, 0, 0, game.TeamRed)
+	if last() != game.ActionForceMark {
+		t.Errorf("Referee should overwrite in normal rule, logged %q", last())
+	}
+
+	r.ResetGame(owner.ID)
+	r.SetRule(owner.ID, RuleSettings{Rule: game.RuleBlackout, Config: r.Game.PhaseConfig})
+	r.StartGame(owner.ID)
+	r.MarkCell(referee.ID, 0, 0, game.TeamRed)
+	r.MarkCell(referee.ID, 0, 0, game.TeamBlue)
+	if last() != game.ActionMark || len(r.Game.Board.Cells[0][0].Marks) != 2 {
+		t.Errorf("Referee should mark like a team in blackout rule, logged %q", last())
+	}
+}
+
+func TestDisputeQueue(t *testing.T) {
+	r, owner := newTestRoom(t)
+	red := addPlayer(t, r, "red", game.TeamRed)
+	blue := addPlayer(t, r, "blue", game.TeamBlue)
+	referee := addReferee(t, r)
+	r.SetSeries(owner.ID, 3)
+	r.StartGame(owner.ID)
+
+	r.MarkCell(red.ID, 0, 0, game.TeamRed)
+	r.MarkCell(red.ID, 1, 1, game.TeamRed)
+	r.DisputeCell(blue.ID, 0, 0, game.TeamRed, "wrong item")
+	r.DisputeCell(blue.ID, 1, 1, game.TeamRed, "too early")
+	if queue := r.GetState().DisputeQueue; len(queue) != 2 || queue[0].ID != 1 {
+		t.Fatalf("Queue should hold both open disputes oldest first, got %+v", queue)
+	}
+
+	// Undoing the disputed mark closes its dispute instead of leaving it unrevertable
+	if err := r.Undo(referee.ID); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if r.Disputes[1].Status != game.DisputeClosed {
+		t.Errorf("Dispute of an undone mark should be closed, got %q", r.Disputes[1].Status)
+	}
+	if queue := r.GetState().DisputeQueue; len(queue) != 1 || queue[0].ID != 1 {
+		t.Errorf("Queue should hold the remaining open dispute, got %+v", queue)
+	}
+
+	if err := r.ResolveDispute(referee.ID, 1, game.DisputeReverted); err != nil {
+		t.Fatalf("ResolveDispute failed: %v", err)
+	}
+	if r.Disputes[0].Status != game.DisputeReverted || len(r.GetState().DisputeQueue) != 0 {
+		t.Errorf("Reverted dispute should leave the queue, got %+v", r.Disputes)
+	}
+
+	// Finishing and resetting archives the game's disputes in the series
+	for col := 0; col < r.Game.Board.Size(); col++ {
+		r.MarkCell(red.ID, 2, col, game.TeamRed)
+	}
+	r.DisputeCell(blue.ID, 2, 0, game.TeamRed, "missed it")
+	r.ResetGame(owner.ID)
+	if len(r.Series.Disputes) != 1 || len(r.Series.Disputes[0]) != 3 {
+		t.Fatalf("Series should archive the game's 3 disputes, got %+v", r.Series.Disputes)
+	}
+	if r.Series.Disputes[0][2].Status != game.DisputeClosed {
+		t.Errorf("Open dispute should be closed by the reset, got %q", r.Series.Disputes[0][2].Status)
+	}
+}
+
+func TestGoalPoolVerifiable(t *testing.T) {
+	r, owner := newTestRoom(t)
+	red := addPlayer(t, r, "red", game.TeamRed)
+	goals := make([]game.Goal, 30)
+	for i := range goals {
+		goals[i] = game.Goal{Text: fmt.Sprintf("goal %d", i+1), Difficulty: i%5 + 1}
+	}
+	if err := r.SetGoalPool(owner.ID, goals); err != nil {
+		t.Fatalf("SetGoalPool failed: %v", err)
+	}
+	seed := int64(42)
+	if err := r.GenerateBoard(owner.ID, &seed); err != nil {
+		t.Fatalf("GenerateBoard failed: %v", err)
+	}
+
+	if _, err := r.GetGoalPool(red.ID); err == nil {
+		t.Error("Players should not see the pool before the game starts")
+	}
+	r.StartGame(owner.ID)
+	if err := r.SetGoalPool(owner.ID, nil); err == nil {
+		t.Error("Pool should be fixed while the game runs")
+	}
+
+	// Players can regenerate the board from the pool and the seed
+	pool, err := r.GetGoalPool(red.ID)
+	if err != nil {
+		t.Fatalf("Players should see the pool once started, got: %v", err)
+	}
+	texts, err := game.GenerateBoard(pool, r.Game.Board.Size(), *r.Game.Seed)
+	if err != nil {
+		t.Fatalf("GenerateBoard failed: %v", err)
+	}
+	for i, text := range texts {
+		if cell := r.Game.Board.Cells[i/5][i%5]; cell.Text != text {
+			t.Fatalf("Regenerated board differs at %d: %q vs %q", i, text, cell.Text)
+		}
+	}
+}
+
+func TestSetRuleLogsReset(t *testing.T) {
+	r, owner := newTestRoom(t)
+	red := addPlayer(t, r, "red", game.TeamRed)
+	r.StartGame(owner.ID)
+	for col := 0; col < r.Game.Board.Size(); col++ {
+		r.MarkCell(red.ID, 0, col, game.TeamRed)
+	}
+
+	if err := r.SetRule(owner.ID, RuleSettings{Rule: game.RuleBlackout, Config: r.Game.PhaseConfig}); err != nil {
+		t.Fatalf("SetRule failed: %v", err)
+	}
+	if last := r.Events[len(r.Events)-1]; last.Action != game.ActionReset {
+		t.Fatalf("Clearing a finished game should log a reset, logged %q", last.Action)
+	}
+	g, err := r.ReplayToSeq(r.Events.LastSeq())
+	if err != nil {
+		t.Fatalf("ReplayToSeq failed: %v", err)
+	}
+	if g.Status != game.StatusWaiting || g.Board.Cells[0][0].MarkedBy() != game.TeamNone {
+		t.Errorf("Replay after the reset should not return the previous game, got %v", g.Status)
+	}
+
+	// Changing the rule of a game that never started logs nothing
+	n := len(r.Events)
+	r.SetRule(owner.ID, RuleSettings{Rule: game.RuleNormal, Config: r.Game.PhaseConfig})
+	if len(r.Events) != n {
+		t.Errorf("Waiting game should log no reset, got %d new events", len(r.Events)-n)
+	}
+}
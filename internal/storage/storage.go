@@ -11,11 +11,19 @@ import (
 
 // RoomData represents the persistable room state
 type RoomData struct {
-	ID          string     `json:"id"`
-	Name        string     `json:"name"`
-	Password    string     `json:"password"`
-	Game        *game.Game `json:"game"`
-	StreamToken string     `json:"stream_token,omitempty"`
+	ID             string         `json:"id"`
+	Name           string         `json:"name"`
+	Password       string         `json:"password"`
+	Game           *game.Game     `json:"game"`
+	StreamToken    string         `json:"stream_token,omitempty"`
+	MaxTeamSize    int            `json:"max_team_size,omitempty"`
+	EventRetention int            `json:"event_retention,omitempty"`
+	HiddenBoard    bool           `json:"hidden_board,omitempty"`
+	PublicIntents  bool           `json:"public_intents,omitempty"`
+	GoalPool       []game.Goal    `json:"goal_pool,omitempty"`
+	Series         *game.Series   `json:"series,omitempty"`
+	Disputes       []game.Dispute `json:"disputes,omitempty"`
+	Events         []game.Event   `json:"events,omitempty"`
 }
 
 // Storage handles persistence using Badger
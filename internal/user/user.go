@@ -11,7 +11,7 @@ type UserRole int
 
 const (
 	RoleSpectator UserRole = iota // Spectator - can only watch
-	RolePlayer                    // Player - can only mark own color
+	RolePlayer                    // Player - can only mark for own team
 	RoleReferee                   // Referee - can do all operations
 )
 
@@ -41,53 +41,21 @@ func UserRoleFromString(s string) UserRole {
 	}
 }
 
-// PlayerColor represents which color a player is assigned to
-type PlayerColor int
-
-const (
-	ColorNone PlayerColor = iota
-	ColorRed
-	ColorBlue
-)
-
-func (c PlayerColor) String() string {
-	switch c {
-	case ColorRed:
-		return "red"
-	case ColorBlue:
-		return "blue"
-	default:
-		return "none"
-	}
-}
-
-func PlayerColorFromString(s string) PlayerColor {
-	switch s {
-	case "red":
-		return ColorRed
-	case "blue":
-		return ColorBlue
-	default:
-		return ColorNone
-	}
-}
-
 // User represents a connected user
 type User struct {
-	ID          string      `json:"id"`
-	Name        string      `json:"name"`
-	Role        UserRole    `json:"role"`
-	PlayerColor PlayerColor `json:"player_color"`
-	RoomID      string      `json:"room_id,omitempty"`
+	ID     string   `json:"id"`
+	Name   string   `json:"name"`
+	Role   UserRole `json:"role"`
+	Team   string   `json:"team,omitempty"` // ID of the team a player belongs to
+	RoomID string   `json:"room_id,omitempty"`
 }
 
 // NewUser creates a new user with a random ID
 func NewUser(name string) *User {
 	return &User{
-		ID:          generateID(),
-		Name:        name,
-		Role:        RoleSpectator,
-		PlayerColor: ColorNone,
+		ID:   generateID(),
+		Name: name,
+		Role: RoleSpectator,
 	}
 }
 
@@ -10,7 +10,10 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
+	"math"
+	"slices"
 	"sync"
 	"time"
 
@@ -31,6 +34,7 @@ type Handler struct {
 	streamTokens   sync.Map                    // token -> roomID (in-memory index for fast lookup)
 	sseSubscribers map[string][]*sseSubscriber // roomID -> subscribers
 	sseSubMu       sync.RWMutex                // protects sseSubscribers
+	clockTimers    sync.Map                    // roomID -> *time.Timer for the next countdown reveal or time limit
 }
 
 // NewHandler creates a new WebSocket handler
@@ -42,6 +46,9 @@ func NewHandler(store *storage.Storage, emptyTTL time.Duration) *Handler {
 	}
 
 	h.roomManager = room.NewManager(emptyTTL, func(id string, immediate bool) {
+		if t, ok := h.clockTimers.LoadAndDelete(id); ok {
+			t.(*time.Timer).Stop()
+		}
 		if store != nil {
 			store.DeleteRoom(id)
 		}
@@ -131,6 +138,8 @@ func (h *Handler) OnMessage(socket *gws.Conn, message *gws.Message) {
 		h.handleSetRole(socket, &msg)
 	case protocol.MsgListRooms:
 		h.handleListRooms(socket)
+	case protocol.MsgGetRules:
+		h.handleGetRules(socket)
 	case protocol.MsgSetPassword:
 		h.handleSetPassword(socket, &msg)
 	case protocol.MsgSetRule:
@@ -149,6 +158,54 @@ func (h *Handler) OnMessage(socket *gws.Conn, message *gws.Message) {
 		h.handleSetCellText(socket, &msg)
 	case protocol.MsgSettle:
 		h.handleSettle(socket, &msg)
+	case protocol.MsgSetBoardSize:
+		h.handleSetBoardSize(socket, &msg)
+	case protocol.MsgSetTeams:
+		h.handleSetTeams(socket, &msg)
+	case protocol.MsgSetTeamSize:
+		h.handleSetTeamSize(socket, &msg)
+	case protocol.MsgSetEventRetention:
+		h.handleSetEventRetention(socket, &msg)
+	case protocol.MsgGetEventLog:
+		h.handleGetEventLog(socket, &msg)
+	case protocol.MsgUndo:
+		h.handleUndo(socket, &msg)
+	case protocol.MsgRedo:
+		h.handleRedo(socket, &msg)
+	case protocol.MsgGetReplay:
+		h.handleGetReplay(socket, &msg)
+	case protocol.MsgSetLinesToWin:
+		h.handleSetLinesToWin(socket, &msg)
+	case protocol.MsgSetSeries:
+		h.handleSetSeries(socket, &msg)
+	case protocol.MsgSetVerifyClaims:
+		h.handleSetVerifyClaims(socket, &msg)
+	case protocol.MsgConfirmClaim, protocol.MsgRejectClaim:
+		h.handleClaim(socket, &msg)
+	case protocol.MsgRaiseProgress, protocol.MsgLowerProgress:
+		h.handleProgress(socket, &msg)
+	case protocol.MsgSetIntent:
+		h.handleSetIntent(socket, &msg)
+	case protocol.MsgSetPublicIntents:
+		h.handleSetPublicIntents(socket, &msg)
+	case protocol.MsgSetCellLock:
+		h.handleSetCellLock(socket, &msg)
+	case protocol.MsgDisputeCell:
+		h.handleDisputeCell(socket, &msg)
+	case protocol.MsgResolveDispute:
+		h.handleResolveDispute(socket, &msg)
+	case protocol.MsgSetTimeLimit:
+		h.handleSetTimeLimit(socket, &msg)
+	case protocol.MsgSetCountdown:
+		h.handleSetCountdown(socket, &msg)
+	case protocol.MsgSetHiddenBoard:
+		h.handleSetHiddenBoard(socket, &msg)
+	case protocol.MsgSetGoalPool:
+		h.handleSetGoalPool(socket, &msg)
+	case protocol.MsgGetGoalPool:
+		h.handleGetGoalPool(socket, &msg)
+	case protocol.MsgGenerateBoard:
+		h.handleGenerateBoard(socket, &msg)
 	case protocol.MsgCreateStreamToken:
 		h.handleCreateStreamToken(socket, &msg)
 	default:
@@ -229,21 +286,10 @@ func (h *Handler) handleCreateRoom(socket *gws.Conn, msg *protocol.Message) {
 	h.saveRoomState(r)
 
 	// Send state update in correct format
-	state := r.GetState()
 	h.sendToSocket(socket, protocol.Message{
-		Type:   protocol.MsgJoined,
-		RoomID: r.ID,
-		Payload: mustMarshal(protocol.StateUpdatePayload{
-			Room: protocol.RoomPayload{
-				ID:          state.ID,
-				Name:        state.Name,
-				OwnerID:     state.OwnerID,
-				HasPassword: state.HasPassword,
-			},
-			Game:        convertGame(state.Game),
-			Users:       convertUsers(state.Users),
-			CurrentUser: msg.UserID,
-		}),
+		Type:    protocol.MsgJoined,
+		RoomID:  r.ID,
+		Payload: mustMarshal(newStatePayload(r.GetState(), msg.UserID)),
 	})
 }
 
@@ -326,9 +372,9 @@ func (h *Handler) handleSetRole(socket *gws.Conn, msg *protocol.Message) {
 	}
 
 	role := user.UserRoleFromString(payload.Role)
-	color := user.PlayerColorFromString(payload.PlayerColor)
+	team := game.TeamIDFromString(payload.Team)
 
-	if err := r.SetUserRole(msg.UserID, payload.TargetUserID, role, color); err != nil {
+	if err := r.SetUserRole(msg.UserID, payload.TargetUserID, role, team); err != nil {
 		h.sendError(socket, 403, err.Error())
 		return
 	}
@@ -348,6 +394,31 @@ func (h *Handler) handleListRooms(socket *gws.Conn) {
 	})
 }
 
+// handleGetRules sends the registered rules and their config fields
+func (h *Handler) handleGetRules(socket *gws.Conn) {
+	rules := game.Rules()
+	result := make([]protocol.RuleInfoPayload, len(rules))
+	for i, id := range rules {
+		rule := id.Impl()
+		fields := rule.ConfigSchema()
+		config := make([]protocol.ConfigFieldPayload, len(fields))
+		for j, f := range fields {
+			config[j] = protocol.ConfigFieldPayload{
+				Key:         f.Key,
+				Type:        f.Type,
+				Min:         f.Min,
+				Description: f.Description,
+			}
+		}
+		result[i] = protocol.RuleInfoPayload{Name: rule.Name(), Config: config}
+	}
+
+	h.sendToSocket(socket, protocol.Message{
+		Type:    protocol.MsgRules,
+		Payload: mustMarshal(protocol.RulesPayload{Rules: result}),
+	})
+}
+
 // handleSetPassword handles setting room password
 func (h *Handler) handleSetPassword(socket *gws.Conn, msg *protocol.Message) {
 	var payload struct {
@@ -387,33 +458,56 @@ func (h *Handler) handleSetRule(socket *gws.Conn, msg *protocol.Message) {
 		return
 	}
 
-	rule := game.GameRuleFromString(payload.Rule)
-	config := game.DefaultPhaseConfig()
-
-	if len(payload.PhaseConfig.RowScores) == 5 {
-		for i, v := range payload.PhaseConfig.RowScores {
-			config.RowScores[i] = v
-		}
+	rule, ok := game.LookupRule(payload.Rule)
+	if !ok {
+		h.sendError(socket, 400, "unknown rule")
+		return
 	}
-	if len(payload.PhaseConfig.SecondHalfScores) == 5 {
-		for i, v := range payload.PhaseConfig.SecondHalfScores {
-			config.SecondHalfScores[i] = v
+	// Omitted fields keep their defaults, anything given is validated by the game
+	config := game.DefaultPhaseConfig(r.GetBoardSize())
+	if payload.PhaseConfig.RowScores != nil {
+		config.RowScores = payload.PhaseConfig.RowScores
+	}
+	if payload.PhaseConfig.SecondHalfScores != nil {
+		config.SecondHalfScores = payload.PhaseConfig.SecondHalfScores
+	}
+	for _, f := range []struct {
+		value *int
+		dst   *int
+	}{
+		{payload.PhaseConfig.CellsPerRow, &config.CellsPerRow},
+		{payload.PhaseConfig.UnlockThreshold, &config.UnlockThreshold},
+		{payload.PhaseConfig.BingoBonus, &config.BingoBonus},
+		{payload.PhaseConfig.FinalBonus, &config.FinalBonus},
+	} {
+		if f.value != nil {
+			*f.dst = *f.value
 		}
 	}
-	if payload.PhaseConfig.CellsPerRow > 0 {
-		config.CellsPerRow = payload.PhaseConfig.CellsPerRow
-	}
-	if payload.PhaseConfig.UnlockThreshold > 0 {
-		config.UnlockThreshold = payload.PhaseConfig.UnlockThreshold
+	config.Phases = convertPhasesFromPayload(payload.PhaseConfig.Phases)
+	if err := config.Validate(r.GetBoardSize()); err != nil {
+		h.sendConfigError(socket, err)
+		return
 	}
-	if payload.PhaseConfig.BingoBonus > 0 {
-		config.BingoBonus = payload.PhaseConfig.BingoBonus
+
+	settings := room.RuleSettings{
+		Rule:          rule,
+		Config:        config,
+		PointsTarget:  payload.PointsTarget,
+		WinPatterns:   payload.WinPatterns,
+		CustomPattern: payload.CustomPattern,
+		FreeCenter:    payload.FreeCenter,
 	}
-	if payload.PhaseConfig.FinalBonus > 0 {
-		config.FinalBonus = payload.PhaseConfig.FinalBonus
+	if payload.Handicaps != nil {
+		handicaps, err := convertHandicapsFromPayload(payload.Handicaps)
+		if err != nil {
+			h.sendError(socket, 400, err.Error())
+			return
+		}
+		settings.Handicaps = handicaps
 	}
-
-	if err := r.SetGameRule(msg.UserID, rule, config); err != nil {
+	// Nothing is changed if any setting is refused
+	if err := r.SetRule(msg.UserID, settings); err != nil {
 		h.sendError(socket, 403, err.Error())
 		return
 	}
@@ -435,13 +529,14 @@ func (h *Handler) handleStartGame(socket *gws.Conn, msg *protocol.Message) {
 		return
 	}
 
+	h.scheduleClock(r)
 	h.broadcastRoomState(r)
 	h.saveRoomState(r)
 }
 
-// handleMarkCell handles marking a cell
-func (h *Handler) handleMarkCell(socket *gws.Conn, msg *protocol.Message) {
-	var payload protocol.MarkCellPayload
+// handleSetVerifyClaims handles turning claim verification on or off
+func (h *Handler) handleSetVerifyClaims(socket *gws.Conn, msg *protocol.Message) {
+	var payload protocol.SetVerifyClaimsPayload
 	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
 		h.sendError(socket, 400, "invalid payload")
 		return
@@ -453,8 +548,7 @@ func (h *Handler) handleMarkCell(socket *gws.Conn, msg *protocol.Message) {
 		return
 	}
 
-	color := game.PlayerColorFromString(payload.Color)
-	if err := r.MarkCell(msg.UserID, payload.Row, payload.Col, color); err != nil {
+	if err := r.SetVerifyClaims(msg.UserID, payload.Verify); err != nil {
 		h.sendError(socket, 403, err.Error())
 		return
 	}
@@ -463,9 +557,9 @@ func (h *Handler) handleMarkCell(socket *gws.Conn, msg *protocol.Message) {
 	h.saveRoomState(r)
 }
 
-// handleUnmarkCell handles unmarking a cell
-func (h *Handler) handleUnmarkCell(socket *gws.Conn, msg *protocol.Message) {
-	var payload protocol.MarkCellPayload
+// handleClaim handles the referee confirming or rejecting a pending claim
+func (h *Handler) handleClaim(socket *gws.Conn, msg *protocol.Message) {
+	var payload protocol.ClaimPayload
 	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
 		h.sendError(socket, 400, "invalid payload")
 		return
@@ -477,7 +571,13 @@ func (h *Handler) handleUnmarkCell(socket *gws.Conn, msg *protocol.Message) {
 		return
 	}
 
-	if err := r.UnmarkCell(msg.UserID, payload.Row, payload.Col); err != nil {
+	team := game.TeamIDFromString(payload.Team)
+	if msg.Type == protocol.MsgConfirmClaim {
+		err = r.ConfirmClaim(msg.UserID, payload.Row, payload.Col, team)
+	} else {
+		err = r.RejectClaim(msg.UserID, payload.Row, payload.Col, team)
+	}
+	if err != nil {
 		h.sendError(socket, 403, err.Error())
 		return
 	}
@@ -486,9 +586,9 @@ func (h *Handler) handleUnmarkCell(socket *gws.Conn, msg *protocol.Message) {
 	h.saveRoomState(r)
 }
 
-// handleClearCellMark handles clearing a specific color mark from a cell
-func (h *Handler) handleClearCellMark(socket *gws.Conn, msg *protocol.Message) {
-	var payload protocol.ClearCellMarkPayload
+// handleSetCellLock handles the referee locking, voiding or reopening a cell
+func (h *Handler) handleSetCellLock(socket *gws.Conn, msg *protocol.Message) {
+	var payload protocol.SetCellLockPayload
 	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
 		h.sendError(socket, 400, "invalid payload")
 		return
@@ -500,25 +600,33 @@ func (h *Handler) handleClearCellMark(socket *gws.Conn, msg *protocol.Message) {
 		return
 	}
 
-	color := game.PlayerColorFromString(payload.Color)
-	if err := r.ClearCellMark(msg.UserID, payload.Row, payload.Col, color); err != nil {
+	if err := r.SetCellLock(msg.UserID, payload.Row, payload.Col, game.CellLock(payload.Lock)); err != nil {
 		h.sendError(socket, 403, err.Error())
 		return
 	}
 
 	h.broadcastRoomState(r)
 	h.saveRoomState(r)
+	h.scheduleClock(r)
 }
 
-// handleResetGame handles resetting a game
-func (h *Handler) handleResetGame(socket *gws.Conn, msg *protocol.Message) {
+// handleProgress handles raising or lowering a team's counter on a cell
+func (h *Handler) handleProgress(socket *gws.Conn, msg *protocol.Message) {
+	var payload protocol.ProgressPayload
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		h.sendError(socket, 400, "invalid payload")
+		return
+	}
+
 	_, r, err := h.getUserAndRoom(msg.UserID)
 	if err != nil {
 		h.sendError(socket, 404, err.Error())
 		return
 	}
 
-	if err := r.ResetGame(msg.UserID); err != nil {
+	team := game.TeamIDFromString(payload.Team)
+	up := msg.Type == protocol.MsgRaiseProgress
+	if err := r.AddProgress(msg.UserID, payload.Row, payload.Col, team, up); err != nil {
 		h.sendError(socket, 403, err.Error())
 		return
 	}
@@ -527,9 +635,10 @@ func (h *Handler) handleResetGame(socket *gws.Conn, msg *protocol.Message) {
 	h.saveRoomState(r)
 }
 
-// handleSetCellText handles setting cell text
-func (h *Handler) handleSetCellText(socket *gws.Conn, msg *protocol.Message) {
-	var payload protocol.SetCellTextPayload
+// handleSetIntent handles a player showing or hiding what they are working on
+// Intents are not saved on their own, they go to storage with the next saved change
+func (h *Handler) handleSetIntent(socket *gws.Conn, msg *protocol.Message) {
+	var payload protocol.SetIntentPayload
 	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
 		h.sendError(socket, 400, "invalid payload")
 		return
@@ -541,15 +650,29 @@ func (h *Handler) handleSetCellText(socket *gws.Conn, msg *protocol.Message) {
 		return
 	}
 
-	if len(payload.Texts) > 0 {
-		// Batch set
-		err = r.SetAllCellTexts(msg.UserID, payload.Texts)
-	} else {
-		// Single set
-		err = r.SetCellText(msg.UserID, payload.Row, payload.Col, payload.Text)
+	if err := r.SetIntent(msg.UserID, payload.Row, payload.Col, payload.Active); err != nil {
+		h.sendError(socket, 403, err.Error())
+		return
+	}
+
+	h.broadcastRoomState(r)
+}
+
+// handleSetPublicIntents handles showing intent markers to everyone
+func (h *Handler) handleSetPublicIntents(socket *gws.Conn, msg *protocol.Message) {
+	var payload protocol.SetPublicIntentsPayload
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		h.sendError(socket, 400, "invalid payload")
+		return
 	}
 
+	_, r, err := h.getUserAndRoom(msg.UserID)
 	if err != nil {
+		h.sendError(socket, 404, err.Error())
+		return
+	}
+
+	if err := r.SetPublicIntents(msg.UserID, payload.Public); err != nil {
 		h.sendError(socket, 403, err.Error())
 		return
 	}
@@ -558,9 +681,9 @@ func (h *Handler) handleSetCellText(socket *gws.Conn, msg *protocol.Message) {
 	h.saveRoomState(r)
 }
 
-// handleSettle handles settlement for phase rule
-func (h *Handler) handleSettle(socket *gws.Conn, msg *protocol.Message) {
-	var payload protocol.SettlePayload
+// handleDisputeCell handles a player disputing another team's mark
+func (h *Handler) handleDisputeCell(socket *gws.Conn, msg *protocol.Message) {
+	var payload protocol.DisputeCellPayload
 	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
 		h.sendError(socket, 400, "invalid payload")
 		return
@@ -572,8 +695,8 @@ func (h *Handler) handleSettle(socket *gws.Conn, msg *protocol.Message) {
 		return
 	}
 
-	player := game.PlayerColorFromString(payload.Player)
-	if err := r.Settle(msg.UserID, player); err != nil {
+	team := game.TeamIDFromString(payload.Team)
+	if err := r.DisputeCell(msg.UserID, payload.Row, payload.Col, team, payload.Reason); err != nil {
 		h.sendError(socket, 403, err.Error())
 		return
 	}
@@ -582,236 +705,1209 @@ func (h *Handler) handleSettle(socket *gws.Conn, msg *protocol.Message) {
 	h.saveRoomState(r)
 }
 
-// sendToSocket sends a message to a socket
-func (h *Handler) sendToSocket(socket *gws.Conn, msg protocol.Message) {
-	data, _ := json.Marshal(msg)
-	socket.WriteMessage(gws.OpcodeText, data)
-}
-
-// sendError sends an error message
-func (h *Handler) sendError(socket *gws.Conn, code int, message string) {
-	h.sendToSocket(socket, protocol.Message{
-		Type: protocol.MsgError,
-		Payload: mustMarshal(protocol.ErrorPayload{
-			Code:    code,
-			Message: message,
-		}),
-	})
-}
+// handleResolveDispute handles the referee upholding or reverting a disputed mark
+func (h *Handler) handleResolveDispute(socket *gws.Conn, msg *protocol.Message) {
+	var payload protocol.ResolveDisputePayload
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		h.sendError(socket, 400, "invalid payload")
+		return
+	}
 
-// broadcastRoomState broadcasts the room state to all users in the room and SSE subscribers.
-// It uses r.GetState() to obtain a consistent snapshot under the room's read lock,
-// avoiding direct iteration of r.Users which would be a data race.
-func (h *Handler) broadcastRoomState(r *room.Room) {
-	state := r.GetState()
+	_, r, err := h.getUserAndRoom(msg.UserID)
+	if err != nil {
+		h.sendError(socket, 404, err.Error())
+		return
+	}
 
-	basePayload := protocol.StateUpdatePayload{
-		Room: protocol.RoomPayload{
-			ID:          state.ID,
-			Name:        state.Name,
-			OwnerID:     state.OwnerID,
-			HasPassword: state.HasPassword,
-		},
-		Game:        convertGame(state.Game),
-		Users:       convertUsers(state.Users),
-		CurrentUser: "", // Will be set per user
+	if err := r.ResolveDispute(msg.UserID, payload.ID, game.DisputeStatus(payload.Resolution)); err != nil {
+		h.sendError(socket, 403, err.Error())
+		return
 	}
 
-	basePayloadBytes := mustMarshal(basePayload)
+	h.scheduleClock(r)
+	h.broadcastRoomState(r)
+	h.saveRoomState(r)
+}
 
-	msg := protocol.Message{
-		Type:    protocol.MsgStateUpdate,
-		RoomID:  r.ID,
-		Payload: basePayloadBytes,
+// handleSetSeries handles starting or ending a best-of-N series
+func (h *Handler) handleSetSeries(socket *gws.Conn, msg *protocol.Message) {
+	var payload protocol.SetSeriesPayload
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		h.sendError(socket, 400, "invalid payload")
+		return
 	}
 
-	// Send to WebSocket users using the snapshot from GetState()
-	for _, u := range state.Users {
-		if conn, ok := h.connections.Load(u.ID); ok {
-			msgCopy := msg
-			var payload protocol.StateUpdatePayload
-			if err := json.Unmarshal(basePayloadBytes, &payload); err != nil {
-				log.Printf("broadcastRoomState: unmarshal error for user %s: %v", u.ID, err)
-				continue
-			}
-			payload.CurrentUser = u.ID
-			msgCopy.Payload = mustMarshal(payload)
-			h.sendToSocket(conn.(*gws.Conn), msgCopy)
-		}
+	_, r, err := h.getUserAndRoom(msg.UserID)
+	if err != nil {
+		h.sendError(socket, 404, err.Error())
+		return
 	}
 
-	// Push to SSE subscribers for this room
-	h.pushToSSESubscribers(r.ID, basePayloadBytes)
-}
-
-// Helper functions
+	if err := r.SetSeries(msg.UserID, payload.BestOf); err != nil {
+		h.sendError(socket, 403, err.Error())
+		return
+	}
 
-func mustMarshal(v interface{}) json.RawMessage {
-	data, _ := json.Marshal(v)
-	return data
+	h.broadcastRoomState(r)
+	h.saveRoomState(r)
 }
 
-// getUserAndRoom validates and returns user and room for handlers that require both
-func (h *Handler) getUserAndRoom(userID string) (*user.User, *room.Room, error) {
-	u := h.userManager.GetUser(userID)
-	if u == nil {
-		return nil, nil, errors.New("user not found")
-	}
-	if u.RoomID == "" {
-		return nil, nil, errors.New("user not in room")
+// handleSetLinesToWin handles setting the lines needed to win in normal rule
+func (h *Handler) handleSetLinesToWin(socket *gws.Conn, msg *protocol.Message) {
+	var payload protocol.SetLinesToWinPayload
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		h.sendError(socket, 400, "invalid payload")
+		return
 	}
-	r := h.roomManager.GetRoom(u.RoomID)
-	if r == nil {
-		return nil, nil, errors.New("room not found")
+
+	_, r, err := h.getUserAndRoom(msg.UserID)
+	if err != nil {
+		h.sendError(socket, 404, err.Error())
+		return
 	}
-	return u, r, nil
-}
 
-func convertRooms(rooms []room.RoomInfo) []protocol.RoomPayload {
-	result := make([]protocol.RoomPayload, len(rooms))
-	for i, r := range rooms {
-		result[i] = protocol.RoomPayload{
-			ID:          r.ID,
-			Name:        r.Name,
-			HasPassword: r.HasPassword,
-		}
+	if err := r.SetLinesToWin(msg.UserID, payload.Lines); err != nil {
+		h.sendError(socket, 403, err.Error())
+		return
 	}
-	return result
+
+	h.broadcastRoomState(r)
+	h.saveRoomState(r)
 }
 
-func convertGame(g *game.Game) protocol.GamePayload {
-	cells := make([][]protocol.CellPayload, 5)
-	for i := 0; i < 5; i++ {
-		cells[i] = make([]protocol.CellPayload, 5)
-		for j := 0; j < 5; j++ {
-			cells[i][j] = protocol.CellPayload{
-				MarkedBy:   g.Board.Cells[i][j].MarkedBy.String(),
-				SecondMark: g.Board.Cells[i][j].SecondMark.String(),
-				Times:      g.Board.Cells[i][j].Times,
-				Text:       g.Board.Cells[i][j].Text,
-			}
-		}
+// handleSetTimeLimit handles setting the game time limit
+func (h *Handler) handleSetTimeLimit(socket *gws.Conn, msg *protocol.Message) {
+	var payload protocol.SetTimeLimitPayload
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		h.sendError(socket, 400, "invalid payload")
+		return
 	}
 
-	var winner *protocol.WinnerPayload
-	if g.Winner != nil {
-		winner = &protocol.WinnerPayload{
-			Winner:    g.Winner.Winner.String(),
-			Reason:    string(g.Winner.Reason),
-			RedScore:  g.Winner.RedScore,
-			BlueScore: g.Winner.BlueScore,
-		}
+	_, r, err := h.getUserAndRoom(msg.UserID)
+	if err != nil {
+		h.sendError(socket, 404, err.Error())
+		return
 	}
 
-	return protocol.GamePayload{
-		Board: protocol.BoardPayload{
-			Cells: cells,
-		},
-		Rule:            g.Rule.String(),
-		PhaseConfig:     convertPhaseConfig(g.PhaseConfig),
-		Status:          g.Status.String(),
-		Winner:          winner,
-		RedRowMarks:     g.RedRowMarks[:],
-		BlueRowMarks:    g.BlueRowMarks[:],
-		RedUnlockedRow:  g.RedUnlockedRow,
-		BlueUnlockedRow: g.BlueUnlockedRow,
-		BingoAchiever:   g.BingoAchiever.String(),
-		BingoLine:       g.BingoLine,
-		RedSettled:      g.RedSettled,
-		BlueSettled:     g.BlueSettled,
-		FirstSettler:    g.FirstSettler.String(),
+	limit, ok := secondsToDuration(payload.TimeLimit, game.MaxTimeLimit)
+	if !ok {
+		h.sendError(socket, 400, game.ErrInvalidTimeLimit.Error())
+		return
 	}
-}
 
-func convertPhaseConfig(c game.PhaseConfig) protocol.PhaseConfigPayload {
-	return protocol.PhaseConfigPayload{
-		RowScores:        c.RowScores[:],
-		SecondHalfScores: c.SecondHalfScores[:],
-		CellsPerRow:      c.CellsPerRow,
-		UnlockThreshold:  c.UnlockThreshold,
-		BingoBonus:       c.BingoBonus,
-		FinalBonus:       c.FinalBonus,
+	if err := r.SetTimeLimit(msg.UserID, limit); err != nil {
+		h.sendError(socket, 403, err.Error())
+		return
 	}
+
+	h.broadcastRoomState(r)
+	h.saveRoomState(r)
 }
 
-func convertUsers(users []room.UserInfo) []protocol.UserPayload {
-	result := make([]protocol.UserPayload, len(users))
-	for i, u := range users {
-		result[i] = protocol.UserPayload{
-			ID:          u.ID,
-			Name:        u.Name,
-			Role:        u.Role,
-			PlayerColor: u.PlayerColor,
-		}
+// handleSetHiddenBoard handles hiding the board from players until the game starts
+func (h *Handler) handleSetHiddenBoard(socket *gws.Conn, msg *protocol.Message) {
+	var payload protocol.SetHiddenBoardPayload
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		h.sendError(socket, 400, "invalid payload")
+		return
 	}
-	return result
-}
 
-// loadRoomsFromStorage loads persisted rooms from storage
-func (h *Handler) loadRoomsFromStorage() {
-	rooms, err := h.storage.LoadAllRooms()
+	_, r, err := h.getUserAndRoom(msg.UserID)
 	if err != nil {
-		log.Printf("Error loading rooms from storage: %v", err)
+		h.sendError(socket, 404, err.Error())
 		return
 	}
 
-	for _, data := range rooms {
-		// Skip finished games
-		if data.Game.Status == game.StatusFinished {
-			h.storage.DeleteRoom(data.ID)
-			continue
-		}
-
-		// Restore room (including its stream token)
-		r := room.RestoreRoom(data.ID, data.Name, data.Password, data.StreamToken, data.Game)
-		h.roomManager.AddRoom(r)
-
-		// Rebuild in-memory token index
-		if data.StreamToken != "" {
-			h.streamTokens.Store(data.StreamToken, data.ID)
-		}
+	if err := r.SetHiddenBoard(msg.UserID, payload.Hidden); err != nil {
+		h.sendError(socket, 403, err.Error())
+		return
 	}
+
+	h.broadcastRoomState(r)
+	h.saveRoomState(r)
 }
 
-// saveRoomState saves room state to storage
-func (h *Handler) saveRoomState(r *room.Room) {
-	if h.storage == nil {
+// handleSetGoalPool handles replacing the room's goal pool
+func (h *Handler) handleSetGoalPool(socket *gws.Conn, msg *protocol.Message) {
+	var payload protocol.SetGoalPoolPayload
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		h.sendError(socket, 400, "invalid payload")
 		return
 	}
-	data := r.GetPersistData()
-	h.storage.SaveRoom(&storage.RoomData{
-		ID:          data.ID,
-		Name:        data.Name,
-		Password:    data.Password,
-		Game:        data.Game,
-		StreamToken: data.StreamToken,
-	})
-}
 
-// handleCreateStreamToken returns (or creates) a persistent stream token for the room.
-// The token is bound to the room, not the user, so it survives server restarts
-// and user reconnections as long as the room exists.
-func (h *Handler) handleCreateStreamToken(socket *gws.Conn, msg *protocol.Message) {
 	_, r, err := h.getUserAndRoom(msg.UserID)
 	if err != nil {
 		h.sendError(socket, 404, err.Error())
 		return
 	}
 
-	// Reuse existing room token if available
-	token := r.GetStreamToken()
-	if token == "" {
-		// Generate a new random token (16 bytes = 32 hex chars)
-		tokenBytes := make([]byte, 16)
-		if _, randErr := rand.Read(tokenBytes); randErr != nil {
-			h.sendError(socket, 500, "failed to generate token")
-			return
-		}
-		token = hex.EncodeToString(tokenBytes)
-
-		// Persist token on room and save to storage
-		r.SetStreamToken(token)
-		h.saveRoomState(r)
+	if err := r.SetGoalPool(msg.UserID, convertGoalsFromPayload(payload.Goals)); err != nil {
+		h.sendError(socket, 403, err.Error())
+		return
+	}
+
+	h.broadcastRoomState(r)
+	h.saveRoomState(r)
+}
+
+// handleGetGoalPool sends the room's goal pool to the requester
+func (h *Handler) handleGetGoalPool(socket *gws.Conn, msg *protocol.Message) {
+	_, r, err := h.getUserAndRoom(msg.UserID)
+	if err != nil {
+		h.sendError(socket, 404, err.Error())
+		return
+	}
+
+	goals, err := r.GetGoalPool(msg.UserID)
+	if err != nil {
+		h.sendError(socket, 403, err.Error())
+		return
+	}
+
+	h.sendToSocket(socket, protocol.Message{
+		Type:    protocol.MsgGoalPool,
+		Payload: mustMarshal(protocol.GoalPoolPayload{Goals: convertGoals(goals)}),
+	})
+}
+
+// handleGenerateBoard handles filling the board from the goal pool
+func (h *Handler) handleGenerateBoard(socket *gws.Conn, msg *protocol.Message) {
+	var payload protocol.GenerateBoardPayload
+	if len(msg.Payload) > 0 {
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+			h.sendError(socket, 400, "invalid payload")
+			return
+		}
+	}
+
+	_, r, err := h.getUserAndRoom(msg.UserID)
+	if err != nil {
+		h.sendError(socket, 404, err.Error())
+		return
+	}
+
+	if err := r.GenerateBoard(msg.UserID, payload.Seed); err != nil {
+		h.sendError(socket, 403, err.Error())
+		return
+	}
+
+	h.broadcastRoomState(r)
+	h.saveRoomState(r)
+}
+
+// handleSetCountdown handles setting the countdown before the board is revealed
+func (h *Handler) handleSetCountdown(socket *gws.Conn, msg *protocol.Message) {
+	var payload protocol.SetCountdownPayload
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		h.sendError(socket, 400, "invalid payload")
+		return
+	}
+
+	_, r, err := h.getUserAndRoom(msg.UserID)
+	if err != nil {
+		h.sendError(socket, 404, err.Error())
+		return
+	}
+
+	countdown, ok := secondsToDuration(payload.Countdown, game.MaxCountdown)
+	if !ok {
+		h.sendError(socket, 400, game.ErrInvalidCountdown.Error())
+		return
+	}
+
+	if err := r.SetCountdown(msg.UserID, countdown); err != nil {
+		h.sendError(socket, 403, err.Error())
+		return
+	}
+
+	h.broadcastRoomState(r)
+	h.saveRoomState(r)
+}
+
+// scheduleClock arms the room's clock timer for the next countdown reveal or time limit,
+// replacing any earlier timer. When it fires the clock is applied, the new state is
+// broadcast, and the timer is armed again for the following clock event.
+func (h *Handler) scheduleClock(r *room.Room) {
+	at, ok := r.NextClockEvent()
+	if !ok {
+		return
+	}
+
+	t := time.AfterFunc(time.Until(at), func() {
+		if h.roomManager.GetRoom(r.ID) != r {
+			return // Room was deleted
+		}
+		r.UpdateClock(time.Now())
+		h.broadcastRoomState(r)
+		h.saveRoomState(r)
+		h.scheduleClock(r)
+	})
+	if old, loaded := h.clockTimers.Swap(r.ID, t); loaded {
+		old.(*time.Timer).Stop()
+	}
+}
+
+// handleMarkCell handles marking a cell
+func (h *Handler) handleMarkCell(socket *gws.Conn, msg *protocol.Message) {
+	var payload protocol.MarkCellPayload
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		h.sendError(socket, 400, "invalid payload")
+		return
+	}
+
+	_, r, err := h.getUserAndRoom(msg.UserID)
+	if err != nil {
+		h.sendError(socket, 404, err.Error())
+		return
+	}
+
+	team := game.TeamIDFromString(payload.Team)
+	if err := r.MarkCell(msg.UserID, payload.Row, payload.Col, team); err != nil {
+		h.sendError(socket, 403, err.Error())
+		return
+	}
+
+	h.broadcastRoomState(r)
+	h.saveRoomState(r)
+}
+
+// handleUnmarkCell handles unmarking a cell
+func (h *Handler) handleUnmarkCell(socket *gws.Conn, msg *protocol.Message) {
+	var payload protocol.MarkCellPayload
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		h.sendError(socket, 400, "invalid payload")
+		return
+	}
+
+	_, r, err := h.getUserAndRoom(msg.UserID)
+	if err != nil {
+		h.sendError(socket, 404, err.Error())
+		return
+	}
+
+	if err := r.UnmarkCell(msg.UserID, payload.Row, payload.Col); err != nil {
+		h.sendError(socket, 403, err.Error())
+		return
+	}
+
+	h.broadcastRoomState(r)
+	h.saveRoomState(r)
+}
+
+// handleClearCellMark handles clearing a specific team's mark from a cell
+func (h *Handler) handleClearCellMark(socket *gws.Conn, msg *protocol.Message) {
+	var payload protocol.ClearCellMarkPayload
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		h.sendError(socket, 400, "invalid payload")
+		return
+	}
+
+	_, r, err := h.getUserAndRoom(msg.UserID)
+	if err != nil {
+		h.sendError(socket, 404, err.Error())
+		return
+	}
+
+	team := game.TeamIDFromString(payload.Team)
+	if err := r.ClearCellMark(msg.UserID, payload.Row, payload.Col, team); err != nil {
+		h.sendError(socket, 403, err.Error())
+		return
+	}
+
+	h.broadcastRoomState(r)
+	h.saveRoomState(r)
+}
+
+// handleResetGame handles resetting a game
+func (h *Handler) handleResetGame(socket *gws.Conn, msg *protocol.Message) {
+	_, r, err := h.getUserAndRoom(msg.UserID)
+	if err != nil {
+		h.sendError(socket, 404, err.Error())
+		return
+	}
+
+	if err := r.ResetGame(msg.UserID); err != nil {
+		h.sendError(socket, 403, err.Error())
+		return
+	}
+
+	h.broadcastRoomState(r)
+	h.saveRoomState(r)
+}
+
+// handleSetCellText handles setting cell text
+func (h *Handler) handleSetCellText(socket *gws.Conn, msg *protocol.Message) {
+	var payload protocol.SetCellTextPayload
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		h.sendError(socket, 400, "invalid payload")
+		return
+	}
+
+	_, r, err := h.getUserAndRoom(msg.UserID)
+	if err != nil {
+		h.sendError(socket, 404, err.Error())
+		return
+	}
+
+	if len(payload.Texts) > 0 {
+		// Batch set
+		err = r.SetAllCellTexts(msg.UserID, payload.Texts)
+	} else if len(payload.CellPoints) == 0 && len(payload.CellTargets) == 0 {
+		// Single set
+		err = r.SetCellText(msg.UserID, payload.Row, payload.Col, payload.Text)
+	}
+
+	// Point values go with the texts
+	if err == nil && len(payload.CellPoints) > 0 {
+		err = r.SetAllCellPoints(msg.UserID, payload.CellPoints)
+	}
+	if err == nil && payload.Points != nil {
+		err = r.SetCellPoints(msg.UserID, payload.Row, payload.Col, *payload.Points)
+	}
+
+	// So do target counts
+	if err == nil && len(payload.CellTargets) > 0 {
+		err = r.SetAllCellTargets(msg.UserID, payload.CellTargets)
+	}
+	if err == nil && payload.Target != nil {
+		err = r.SetCellTarget(msg.UserID, payload.Row, payload.Col, *payload.Target)
+	}
+
+	if err != nil {
+		h.sendError(socket, 403, err.Error())
+		return
+	}
+
+	h.broadcastRoomState(r)
+	h.saveRoomState(r)
+}
+
+// handleSetBoardSize handles changing the board size
+func (h *Handler) handleSetBoardSize(socket *gws.Conn, msg *protocol.Message) {
+	var payload protocol.SetBoardSizePayload
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		h.sendError(socket, 400, "invalid payload")
+		return
+	}
+
+	_, r, err := h.getUserAndRoom(msg.UserID)
+	if err != nil {
+		h.sendError(socket, 404, err.Error())
+		return
+	}
+
+	if err := r.SetBoardSize(msg.UserID, payload.Size); err != nil {
+		h.sendError(socket, 403, err.Error())
+		return
+	}
+
+	h.broadcastRoomState(r)
+	h.saveRoomState(r)
+}
+
+// handleSetTeams handles replacing the team list
+func (h *Handler) handleSetTeams(socket *gws.Conn, msg *protocol.Message) {
+	var payload protocol.SetTeamsPayload
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		h.sendError(socket, 400, "invalid payload")
+		return
+	}
+
+	_, r, err := h.getUserAndRoom(msg.UserID)
+	if err != nil {
+		h.sendError(socket, 404, err.Error())
+		return
+	}
+
+	teams := make([]game.Team, len(payload.Teams))
+	for i, t := range payload.Teams {
+		teams[i] = game.Team{
+			ID:    game.TeamIDFromString(t.ID),
+			Name:  t.Name,
+			Color: t.Color,
+		}
+	}
+
+	if err := r.SetTeams(msg.UserID, teams); err != nil {
+		h.sendError(socket, 403, err.Error())
+		return
+	}
+
+	h.broadcastRoomState(r)
+	h.saveRoomState(r)
+}
+
+// handleSetTeamSize handles setting the per-team player limit
+func (h *Handler) handleSetTeamSize(socket *gws.Conn, msg *protocol.Message) {
+	var payload protocol.SetTeamSizePayload
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		h.sendError(socket, 400, "invalid payload")
+		return
+	}
+
+	_, r, err := h.getUserAndRoom(msg.UserID)
+	if err != nil {
+		h.sendError(socket, 404, err.Error())
+		return
+	}
+
+	if err := r.SetMaxTeamSize(msg.UserID, payload.MaxTeamSize); err != nil {
+		h.sendError(socket, 403, err.Error())
+		return
+	}
+
+	h.broadcastRoomState(r)
+	h.saveRoomState(r)
+}
+
+// handleSetEventRetention handles setting how many games the event log keeps
+func (h *Handler) handleSetEventRetention(socket *gws.Conn, msg *protocol.Message) {
+	var payload protocol.SetEventRetentionPayload
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		h.sendError(socket, 400, "invalid payload")
+		return
+	}
+
+	_, r, err := h.getUserAndRoom(msg.UserID)
+	if err != nil {
+		h.sendError(socket, 404, err.Error())
+		return
+	}
+
+	if err := r.SetEventRetention(msg.UserID, payload.Games); err != nil {
+		h.sendError(socket, 403, err.Error())
+		return
+	}
+
+	h.broadcastRoomState(r)
+	h.saveRoomState(r)
+}
+
+// handleGetEventLog sends the room's event log to the requesting user
+func (h *Handler) handleGetEventLog(socket *gws.Conn, msg *protocol.Message) {
+	var payload protocol.GetEventLogPayload
+	if len(msg.Payload) > 0 {
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+			h.sendError(socket, 400, "invalid payload")
+			return
+		}
+	}
+
+	_, r, err := h.getUserAndRoom(msg.UserID)
+	if err != nil {
+		h.sendError(socket, 404, err.Error())
+		return
+	}
+
+	events := r.GetEvents(payload.Since)
+	lastSeq := payload.Since
+	if len(events) > 0 {
+		lastSeq = events[len(events)-1].Seq
+	}
+
+	h.sendToSocket(socket, protocol.Message{
+		Type: protocol.MsgEventLog,
+		Payload: mustMarshal(protocol.EventLogPayload{
+			Events:  convertEvents(events),
+			LastSeq: lastSeq,
+		}),
+	})
+}
+
+// handleGetReplay sends the game state rebuilt at a point of the event log
+func (h *Handler) handleGetReplay(socket *gws.Conn, msg *protocol.Message) {
+	var payload protocol.GetReplayPayload
+	if len(msg.Payload) > 0 {
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+			h.sendError(socket, 400, "invalid payload")
+			return
+		}
+	}
+
+	_, r, err := h.getUserAndRoom(msg.UserID)
+	if err != nil {
+		h.sendError(socket, 404, err.Error())
+		return
+	}
+
+	var g *game.Game
+	switch {
+	case payload.Time > 0:
+		g, err = r.ReplayToTime(time.UnixMilli(payload.Time))
+	case payload.Seq > 0:
+		g, err = r.ReplayToSeq(payload.Seq)
+	default:
+		g, err = r.ReplayToSeq(math.MaxInt)
+	}
+	if err != nil {
+		h.sendError(socket, 404, err.Error())
+		return
+	}
+
+	state := protocol.ReplayStatePayload{
+		Seq:     payload.Seq,
+		Time:    payload.Time,
+		Game:    convertGame(g),
+		Partial: g.Status != game.StatusFinished,
+	}
+	if payload.Time > 0 {
+		state.Game.Clock = convertClock(g, time.UnixMilli(payload.Time))
+	}
+	if room.BoardHidden(g, true) && !r.SeesHiddenBoard(msg.UserID) {
+		state.Game = withoutCellTexts(state.Game)
+	}
+
+	h.sendToSocket(socket, protocol.Message{
+		Type:    protocol.MsgReplayState,
+		Payload: mustMarshal(state),
+	})
+}
+
+// handleUndo handles the referee stepping the game back one action
+func (h *Handler) handleUndo(socket *gws.Conn, msg *protocol.Message) {
+	_, r, err := h.getUserAndRoom(msg.UserID)
+	if err != nil {
+		h.sendError(socket, 404, err.Error())
+		return
+	}
+
+	if err := r.Undo(msg.UserID); err != nil {
+		h.sendError(socket, 403, err.Error())
+		return
+	}
+
+	// Undoing a finished game reopens it, so its time limit must be watched again
+	h.scheduleClock(r)
+
+	h.broadcastRoomState(r)
+	h.saveRoomState(r)
+}
+
+// handleRedo handles the referee reapplying an undone action
+func (h *Handler) handleRedo(socket *gws.Conn, msg *protocol.Message) {
+	_, r, err := h.getUserAndRoom(msg.UserID)
+	if err != nil {
+		h.sendError(socket, 404, err.Error())
+		return
+	}
+
+	if err := r.Redo(msg.UserID); err != nil {
+		h.sendError(socket, 403, err.Error())
+		return
+	}
+
+	h.scheduleClock(r)
+
+	h.broadcastRoomState(r)
+	h.saveRoomState(r)
+}
+
+// handleSettle handles settlement, the game's rule refuses it if it has none
+func (h *Handler) handleSettle(socket *gws.Conn, msg *protocol.Message) {
+	var payload protocol.SettlePayload
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		h.sendError(socket, 400, "invalid payload")
+		return
+	}
+
+	_, r, err := h.getUserAndRoom(msg.UserID)
+	if err != nil {
+		h.sendError(socket, 404, err.Error())
+		return
+	}
+
+	team := game.TeamIDFromString(payload.Team)
+	if err := r.Settle(msg.UserID, team); err != nil {
+		h.sendError(socket, 403, err.Error())
+		return
+	}
+
+	h.broadcastRoomState(r)
+	h.saveRoomState(r)
+}
+
+// sendToSocket sends a message to a socket
+func (h *Handler) sendToSocket(socket *gws.Conn, msg protocol.Message) {
+	data, _ := json.Marshal(msg)
+	socket.WriteMessage(gws.OpcodeText, data)
+}
+
+// sendError sends an error message
+func (h *Handler) sendError(socket *gws.Conn, code int, message string) {
+	h.sendToSocket(socket, protocol.Message{
+		Type: protocol.MsgError,
+		Payload: mustMarshal(protocol.ErrorPayload{
+			Code:    code,
+			Message: message,
+		}),
+	})
+}
+
+// sendConfigError sends a rejected phase config, naming the field when known
+func (h *Handler) sendConfigError(socket *gws.Conn, err error) {
+	payload := protocol.ErrorPayload{Code: 400, Message: err.Error()}
+	var configErr *game.ConfigError
+	if errors.As(err, &configErr) {
+		payload.Field = "phase_config." + configErr.Field
+		payload.Reason = configErr.Code
+	}
+	h.sendToSocket(socket, protocol.Message{
+		Type:    protocol.MsgError,
+		Payload: mustMarshal(payload),
+	})
+}
+
+// broadcastRoomState broadcasts the room state to all users in the room and SSE subscribers.
+// It uses r.GetState() to obtain a consistent snapshot under the room's read lock,
+// avoiding direct iteration of r.Users which would be a data race.
+func (h *Handler) broadcastRoomState(r *room.Room) {
+	state := r.GetState()
+
+	// Send to WebSocket users using the snapshot from GetState(), each recipient
+	// gets its own payload with only the cell texts it may see
+	for _, u := range state.Users {
+		if conn, ok := h.connections.Load(u.ID); ok {
+			h.sendToSocket(conn.(*gws.Conn), protocol.Message{
+				Type:    protocol.MsgStateUpdate,
+				RoomID:  r.ID,
+				Payload: mustMarshal(newStatePayload(state, u.ID)),
+			})
+		}
+	}
+
+	// Push to SSE subscribers for this room
+	h.pushToSSESubscribers(r.ID, mustMarshal(newStatePayload(state, "")))
+}
+
+// newStatePayload builds the state update for one recipient, without the cell texts
+// the recipient may not see yet. An empty userID stands for overlay viewers.
+func newStatePayload(state *room.RoomState, userID string) protocol.StateUpdatePayload {
+	payload := protocol.StateUpdatePayload{
+		Room: protocol.RoomPayload{
+			ID:             state.ID,
+			Name:           state.Name,
+			OwnerID:        state.OwnerID,
+			HasPassword:    state.HasPassword,
+			MaxTeamSize:    state.MaxTeamSize,
+			EventRetention: state.EventRetention,
+			HiddenBoard:    state.HiddenBoard,
+			PublicIntents:  state.PublicIntents,
+			CanUndo:        state.CanUndo,
+			CanRedo:        state.CanRedo,
+			GoalPoolSize:   state.GoalPoolSize,
+		},
+		Game:        convertGame(state.Game),
+		Series:      convertSeries(state.Series, state.Game.Teams),
+		Disputes:    convertDisputes(state.Disputes),
+		Queue:       convertDisputes(state.DisputeQueue),
+		Users:       convertUsers(state.Users),
+		CurrentUser: userID,
+	}
+	if !state.BoardVisibleTo(userID) {
+		payload.Game = withoutCellTexts(payload.Game)
+	}
+	for _, row := range payload.Game.Board.Cells {
+		for j := range row {
+			row[j].Intents = slices.DeleteFunc(row[j].Intents, func(m protocol.MarkPayload) bool {
+				return !state.SeesIntent(userID, game.TeamID(m.Team))
+			})
+		}
+	}
+	return payload
+}
+
+// withoutCellTexts returns a copy of the game payload with all cell texts blanked
+func withoutCellTexts(g protocol.GamePayload) protocol.GamePayload {
+	cells := make([][]protocol.CellPayload, len(g.Board.Cells))
+	for i, row := range g.Board.Cells {
+		cells[i] = make([]protocol.CellPayload, len(row))
+		for j, cell := range row {
+			cell.Text = ""
+			cells[i][j] = cell
+		}
+	}
+	g.Board.Cells = cells
+	g.Seed = nil // Would give the board away to anyone who knows the pool
+	return g
+}
+
+// Helper functions
+
+func mustMarshal(v interface{}) json.RawMessage {
+	data, _ := json.Marshal(v)
+	return data
+}
+
+// getUserAndRoom validates and returns user and room for handlers that require both
+func (h *Handler) getUserAndRoom(userID string) (*user.User, *room.Room, error) {
+	u := h.userManager.GetUser(userID)
+	if u == nil {
+		return nil, nil, errors.New("user not found")
+	}
+	if u.RoomID == "" {
+		return nil, nil, errors.New("user not in room")
+	}
+	r := h.roomManager.GetRoom(u.RoomID)
+	if r == nil {
+		return nil, nil, errors.New("room not found")
+	}
+	return u, r, nil
+}
+
+func convertRooms(rooms []room.RoomInfo) []protocol.RoomPayload {
+	result := make([]protocol.RoomPayload, len(rooms))
+	for i, r := range rooms {
+		result[i] = protocol.RoomPayload{
+			ID:          r.ID,
+			Name:        r.Name,
+			HasPassword: r.HasPassword,
+		}
+	}
+	return result
+}
+
+func convertGame(g *game.Game) protocol.GamePayload {
+	size := g.Board.Size()
+	cells := make([][]protocol.CellPayload, size)
+	for i := 0; i < size; i++ {
+		cells[i] = make([]protocol.CellPayload, size)
+		for j := 0; j < size; j++ {
+			cell := &g.Board.Cells[i][j]
+			cells[i][j] = protocol.CellPayload{
+				MarkedBy:   cell.MarkedBy().String(),
+				SecondMark: cell.SecondMark().String(),
+				Marks:      convertMarks(cell.Marks),
+				Claims:     convertMarks(cell.Claims),
+				Intents:    convertMarks(cell.Intents),
+				Times:      cell.Times,
+				Text:       cell.Text,
+				Lock:       string(cell.Lock),
+				Points:     cell.Points,
+				Target:     cell.Target,
+				Progress:   convertProgress(cell.Progress, g.Teams),
+			}
+		}
+	}
+
+	teams := make([]protocol.TeamPayload, len(g.Teams))
+	teamStates := make([]protocol.TeamStatePayload, 0, len(g.Teams))
+	completed := g.CompletedLines()
+	var lines []protocol.TeamLinesPayload
+	for i, t := range g.Teams {
+		teams[i] = protocol.TeamPayload{
+			ID:    string(t.ID),
+			Name:  t.Name,
+			Color: t.Color,
+		}
+		if len(completed[t.ID]) > 0 {
+			lines = append(lines, protocol.TeamLinesPayload{
+				Team:  string(t.ID),
+				Lines: completed[t.ID],
+			})
+		}
+		if state, ok := g.TeamStates[t.ID]; ok {
+			teamStates = append(teamStates, protocol.TeamStatePayload{
+				Team:        string(t.ID),
+				RowMarks:    state.RowMarks,
+				UnlockedRow: state.UnlockedRow,
+				Settled:     state.Settled,
+			})
+		}
+	}
+
+	var winner *protocol.WinnerPayload
+	if g.Winner != nil {
+		winner = convertWinner(g.Winner, g.Teams)
+	}
+
+	return protocol.GamePayload{
+		Board: protocol.BoardPayload{
+			Size:  size,
+			Cells: cells,
+		},
+		Seed:           g.Seed,
+		Rule:           g.Rule.String(),
+		PhaseConfig:    convertPhaseConfig(g.PhaseConfig),
+		Status:         g.Status.String(),
+		Winner:         winner,
+		Teams:          teams,
+		TeamStates:     teamStates,
+		BingoAchiever:  g.BingoAchiever.String(),
+		BingoLine:      g.BingoLine,
+		FirstSettler:   g.FirstSettler.String(),
+		LinesToWin:     g.LinesToWin,
+		PointsTarget:   g.PointsTarget,
+		VerifyClaims:   g.VerifyClaims,
+		WinPatterns:    g.WinPatterns,
+		CustomPattern:  g.CustomPattern,
+		FreeCenter:     g.FreeCenter,
+		Handicaps:      convertHandicaps(g.Handicaps),
+		CompletedLines: lines,
+		Clock:          convertClock(g, time.Now()),
+	}
+}
+
+// convertWinner converts a game result, listing scores in team order
+func convertWinner(w *game.Winner, teams []game.Team) *protocol.WinnerPayload {
+	return &protocol.WinnerPayload{
+		Winner:    w.Winner.String(),
+		Reason:    string(w.Reason),
+		Scores:    teamScores(w.Scores, teams),
+		Pattern:   w.Pattern,
+		Cells:     w.Cells,
+		Handicaps: convertHandicaps(w.Handicaps),
+	}
+}
+
+// teamScores lists per-team values in team order
+func teamScores(values map[game.TeamID]int, teams []game.Team) []protocol.TeamScorePayload {
+	scores := make([]protocol.TeamScorePayload, len(teams))
+	for i, t := range teams {
+		scores[i] = protocol.TeamScorePayload{
+			Team:  string(t.ID),
+			Score: values[t.ID],
+		}
+	}
+	return scores
+}
+
+// convertSeries converts a room's series, nil if it plays none
+func convertSeries(s *game.Series, teams []game.Team) *protocol.SeriesPayload {
+	if s == nil {
+		return nil
+	}
+	payload := &protocol.SeriesPayload{
+		BestOf:     s.BestOf,
+		WinsNeeded: s.WinsNeeded(),
+		Wins:       teamScores(s.Wins, teams),
+		Over:       s.Over,
+	}
+	for i := range s.Games {
+		payload.Games = append(payload.Games, *convertWinner(&s.Games[i], teams))
+	}
+	for _, disputes := range s.Disputes {
+		payload.Disputes = append(payload.Disputes, convertDisputes(disputes))
+	}
+	if s.Over {
+		payload.Winner = s.Winner.String()
+	}
+	return payload
+}
+
+func convertClock(g *game.Game, now time.Time) protocol.ClockPayload {
+	clock := protocol.ClockPayload{
+		ServerTime: now.UnixMilli(),
+		StartTime:  unixMilli(g.StartTime),
+		EndTime:    unixMilli(g.EndTime),
+		TimeLimit:  int(g.TimeLimit / time.Second),
+		Countdown:  int(g.Countdown / time.Second),
+	}
+	if deadline, ok := g.Deadline(); ok {
+		clock.Deadline = deadline.UnixMilli()
+	}
+	if reveal, ok := g.RevealTime(); ok {
+		clock.RevealTime = reveal.UnixMilli()
+	}
+	return clock
+}
+
+// secondsToDuration converts seconds from a client to a duration, refusing values
+// outside 0 and max before the conversion can overflow
+func secondsToDuration(seconds int, max time.Duration) (time.Duration, bool) {
+	if seconds < 0 || seconds > int(max/time.Second) {
+		return 0, false
+	}
+	return time.Duration(seconds) * time.Second, true
+}
+
+// unixMilli converts t to Unix milliseconds, keeping the zero time as 0
+func unixMilli(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
+
+// convertProgress lists the teams' counters on a cell in team order, leaving out teams at 0
+func convertProgress(progress map[game.TeamID]int, teams []game.Team) []protocol.TeamCountPayload {
+	var result []protocol.TeamCountPayload
+	for _, t := range teams {
+		if count := progress[t.ID]; count > 0 {
+			result = append(result, protocol.TeamCountPayload{Team: string(t.ID), Count: count})
+		}
+	}
+	return result
+}
+
+func convertDisputes(disputes []game.Dispute) []protocol.DisputePayload {
+	if len(disputes) == 0 {
+		return nil
+	}
+	result := make([]protocol.DisputePayload, len(disputes))
+	for i, d := range disputes {
+		result[i] = protocol.DisputePayload{
+			ID:           d.ID,
+			Time:         unixMilli(d.Time),
+			Row:          d.Row,
+			Col:          d.Col,
+			Team:         string(d.Team),
+			By:           string(d.By),
+			PlayerName:   d.PlayerName,
+			Reason:       d.Reason,
+			Status:       string(d.Status),
+			ResolverName: d.ResolverName,
+			ResolvedAt:   unixMilli(d.ResolvedAt),
+		}
+	}
+	return result
+}
+
+func convertMarks(marks []game.Mark) []protocol.MarkPayload {
+	result := make([]protocol.MarkPayload, len(marks))
+	for i, m := range marks {
+		result[i] = protocol.MarkPayload{
+			Team:       string(m.Team),
+			PlayerID:   m.PlayerID,
+			PlayerName: m.PlayerName,
+		}
+	}
+	return result
+}
+
+func convertGoals(goals []game.Goal) []protocol.GoalPayload {
+	result := make([]protocol.GoalPayload, len(goals))
+	for i, goal := range goals {
+		result[i] = protocol.GoalPayload{
+			Text:       goal.Text,
+			Difficulty: goal.Difficulty,
+			Group:      goal.Group,
+			Tags:       goal.Tags,
+			Target:     goal.Target,
+		}
+	}
+	return result
+}
+
+func convertGoalsFromPayload(goals []protocol.GoalPayload) []game.Goal {
+	result := make([]game.Goal, len(goals))
+	for i, goal := range goals {
+		result[i] = game.Goal{
+			Text:       goal.Text,
+			Difficulty: goal.Difficulty,
+			Group:      goal.Group,
+			Tags:       goal.Tags,
+			Target:     goal.Target,
+		}
+	}
+	return result
+}
+
+func convertEvents(events []game.Event) []protocol.EventPayload {
+	result := make([]protocol.EventPayload, len(events))
+	for i, e := range events {
+		result[i] = protocol.EventPayload{
+			Seq:       e.Seq,
+			Time:      e.Time.UnixMilli(),
+			ActorID:   e.ActorID,
+			ActorName: e.ActorName,
+			Action:    string(e.Action),
+			Team:      string(e.Team),
+		}
+		if e.Action.HasCell() {
+			row, col := e.Row, e.Col
+			result[i].Row = &row
+			result[i].Col = &col
+		}
+	}
+	return result
+}
+
+func convertPhaseConfig(c game.PhaseConfig) protocol.PhaseConfigPayload {
+	return protocol.PhaseConfigPayload{
+		RowScores:        c.RowScores,
+		SecondHalfScores: c.SecondHalfScores,
+		CellsPerRow:      &c.CellsPerRow,
+		UnlockThreshold:  &c.UnlockThreshold,
+		BingoBonus:       &c.BingoBonus,
+		FinalBonus:       &c.FinalBonus,
+		Phases:           convertPhases(c.Phases),
+	}
+}
+
+func convertPhases(phases []game.Phase) []protocol.PhasePayload {
+	if phases == nil {
+		return nil
+	}
+	result := make([]protocol.PhasePayload, len(phases))
+	for i, p := range phases {
+		result[i] = protocol.PhasePayload{
+			Cells:           p.Cells,
+			Score:           p.Score,
+			LaterScore:      p.LaterScore,
+			Limit:           p.Limit,
+			UnlockThreshold: p.UnlockThreshold,
+		}
+	}
+	return result
+}
+
+// convertHandicaps converts the teams' handicaps
+func convertHandicaps(handicaps []game.Handicap) []protocol.HandicapPayload {
+	if len(handicaps) == 0 {
+		return nil
+	}
+	result := make([]protocol.HandicapPayload, len(handicaps))
+	for i, hc := range handicaps {
+		result[i] = protocol.HandicapPayload{
+			Team:        hc.Team.String(),
+			Cells:       hc.Cells,
+			ScoreOffset: hc.ScoreOffset,
+			StartDelay:  int(hc.StartDelay / time.Second),
+		}
+	}
+	return result
+}
+
+// convertHandicapsFromPayload converts handicaps from a set_rule request,
+// refusing start delays out of range before they are converted
+func convertHandicapsFromPayload(handicaps []protocol.HandicapPayload) ([]game.Handicap, error) {
+	result := make([]game.Handicap, len(handicaps))
+	for i, hc := range handicaps {
+		delay, ok := secondsToDuration(hc.StartDelay, game.MaxStartDelay)
+		if !ok {
+			return nil, fmt.Errorf("%w: start delay must be between 0 and %s", game.ErrInvalidHandicap, game.MaxStartDelay)
+		}
+		result[i] = game.Handicap{
+			Team:        game.TeamIDFromString(hc.Team),
+			Cells:       hc.Cells,
+			ScoreOffset: hc.ScoreOffset,
+			StartDelay:  delay,
+		}
+	}
+	return result, nil
+}
+
+func convertPhasesFromPayload(phases []protocol.PhasePayload) []game.Phase {
+	if len(phases) == 0 {
+		return nil
+	}
+	result := make([]game.Phase, len(phases))
+	for i, p := range phases {
+		result[i] = game.Phase{
+			Cells:           p.Cells,
+			Score:           p.Score,
+			LaterScore:      p.LaterScore,
+			Limit:           p.Limit,
+			UnlockThreshold: p.UnlockThreshold,
+		}
+	}
+	return result
+}
+
+func convertUsers(users []room.UserInfo) []protocol.UserPayload {
+	result := make([]protocol.UserPayload, len(users))
+	for i, u := range users {
+		result[i] = protocol.UserPayload{
+			ID:   u.ID,
+			Name: u.Name,
+			Role: u.Role,
+			Team: u.Team,
+		}
+	}
+	return result
+}
+
+// loadRoomsFromStorage loads persisted rooms from storage
+func (h *Handler) loadRoomsFromStorage() {
+	rooms, err := h.storage.LoadAllRooms()
+	if err != nil {
+		log.Printf("Error loading rooms from storage: %v", err)
+		return
+	}
+
+	for _, data := range rooms {
+		// Skip finished games, unless the room's series goes on
+		if data.Game.Status == game.StatusFinished && !data.Series.Running() {
+			h.storage.DeleteRoom(data.ID)
+			continue
+		}
+
+		// Fill in state missing from older saves
+		data.Game.Normalize()
+
+		// Restore room (including its stream token)
+		r := room.RestoreRoom(&room.PersistData{
+			ID:             data.ID,
+			Name:           data.Name,
+			Password:       data.Password,
+			Game:           data.Game,
+			StreamToken:    data.StreamToken,
+			MaxTeamSize:    data.MaxTeamSize,
+			EventRetention: data.EventRetention,
+			HiddenBoard:    data.HiddenBoard,
+			PublicIntents:  data.PublicIntents,
+			GoalPool:       data.GoalPool,
+			Series:         data.Series,
+			Disputes:       data.Disputes,
+			Events:         data.Events,
+		})
+		h.roomManager.AddRoom(r)
+		h.scheduleClock(r)
+
+		// Rebuild in-memory token index
+		if data.StreamToken != "" {
+			h.streamTokens.Store(data.StreamToken, data.ID)
+		}
+	}
+}
+
+// saveRoomState saves room state to storage
+func (h *Handler) saveRoomState(r *room.Room) {
+	if h.storage == nil {
+		return
+	}
+	data := r.GetPersistData()
+	h.storage.SaveRoom(&storage.RoomData{
+		ID:             data.ID,
+		Name:           data.Name,
+		Password:       data.Password,
+		Game:           data.Game,
+		StreamToken:    data.StreamToken,
+		MaxTeamSize:    data.MaxTeamSize,
+		EventRetention: data.EventRetention,
+		HiddenBoard:    data.HiddenBoard,
+		PublicIntents:  data.PublicIntents,
+		GoalPool:       data.GoalPool,
+		Series:         data.Series,
+		Disputes:       data.Disputes,
+		Events:         data.Events,
+	})
+}
+
+// handleCreateStreamToken returns (or creates) a persistent stream token for the room.
+// The token is bound to the room, not the user, so it survives server restarts
+// and user reconnections as long as the room exists.
+func (h *Handler) handleCreateStreamToken(socket *gws.Conn, msg *protocol.Message) {
+	_, r, err := h.getUserAndRoom(msg.UserID)
+	if err != nil {
+		h.sendError(socket, 404, err.Error())
+		return
+	}
+
+	// Reuse existing room token if available
+	token := r.GetStreamToken()
+	if token == "" {
+		// Generate a new random token (16 bytes = 32 hex chars)
+		tokenBytes := make([]byte, 16)
+		if _, randErr := rand.Read(tokenBytes); randErr != nil {
+			h.sendError(socket, 500, "failed to generate token")
+			return
+		}
+		token = hex.EncodeToString(tokenBytes)
+
+		// Persist token on room and save to storage
+		r.SetStreamToken(token)
+		h.saveRoomState(r)
 	}
 
 	// Ensure in-memory index is up to date
@@ -883,17 +1979,62 @@ func (h *Handler) GetRoomState(roomID string) []byte {
 	if r == nil {
 		return nil
 	}
+	return mustMarshal(newStatePayload(r.GetState(), ""))
+}
+
+// GetReplay returns the last match recorded in a room's event log as a serialized ReplayPayload.
+// Frames run from the last game start up to the following reset or the end of the log,
+// a game that has not finished is marked as partial.
+// Returns nil if the room does not exist or has nothing to replay.
+func (h *Handler) GetReplay(roomID string) []byte {
+	r := h.roomManager.GetRoom(roomID)
+	if r == nil {
+		return nil
+	}
+
+	events := r.GetEvents(0)
+	start := -1
+	for i, e := range events {
+		if e.Action == game.ActionStart && e.Game != nil {
+			start = i
+		}
+	}
+	if start < 0 {
+		return nil
+	}
+
+	var p game.Replayer
+	frames := []protocol.ReplayFramePayload{}
+	for _, e := range events[start:] {
+		if e.Action == game.ActionReset {
+			break
+		}
+		if err := p.Apply(e); err != nil {
+			log.Printf("Replay of room %s stopped: %v", roomID, err)
+			break
+		}
+		frame := protocol.ReplayFramePayload{
+			Seq:       e.Seq,
+			Time:      e.Time.UnixMilli(),
+			Action:    string(e.Action),
+			ActorName: e.ActorName,
+			Game:      convertGame(p.Game),
+		}
+		frame.Game.Clock = convertClock(p.Game, e.Time)
+		if room.BoardHidden(p.Game, true) {
+			frame.Game = withoutCellTexts(frame.Game)
+		}
+		frames = append(frames, frame)
+	}
+
 	state := r.GetState()
-	payload := protocol.StateUpdatePayload{
+	return mustMarshal(protocol.ReplayPayload{
 		Room: protocol.RoomPayload{
-			ID:          state.ID,
-			Name:        state.Name,
-			OwnerID:     state.OwnerID,
-			HasPassword: state.HasPassword,
+			ID:   state.ID,
+			Name: state.Name,
 		},
-		Game:        convertGame(state.Game),
-		Users:       convertUsers(state.Users),
-		CurrentUser: "",
-	}
-	return mustMarshal(payload)
+		Users:   convertUsers(state.Users),
+		Frames:  frames,
+		Partial: p.Game == nil || p.Game.Status != game.StatusFinished,
+	})
 }
@@ -4,7 +4,7 @@ import "encoding/json"
 
 // ProtocolVersion is the current protocol version
 // Increment this when making incompatible protocol changes
-const ProtocolVersion = 1
+const ProtocolVersion = 2
 
 // MessageType represents the type of WebSocket message
 type MessageType string
@@ -22,14 +22,41 @@ const (
 	MsgSetPassword MessageType = "set_password"
 
 	// Game operations
-	MsgMarkCell      MessageType = "mark_cell"
-	MsgUnmarkCell    MessageType = "unmark_cell"
-	MsgClearCellMark MessageType = "clear_cell_mark"
-	MsgSetRule       MessageType = "set_rule"
-	MsgStartGame     MessageType = "start_game"
-	MsgResetGame     MessageType = "reset_game"
-	MsgSetCellText   MessageType = "set_cell_text"
-	MsgSettle        MessageType = "settle"
+	MsgMarkCell          MessageType = "mark_cell"
+	MsgUnmarkCell        MessageType = "unmark_cell"
+	MsgClearCellMark     MessageType = "clear_cell_mark"
+	MsgSetRule           MessageType = "set_rule"
+	MsgStartGame         MessageType = "start_game"
+	MsgResetGame         MessageType = "reset_game"
+	MsgSetCellText       MessageType = "set_cell_text"
+	MsgSettle            MessageType = "settle"
+	MsgSetBoardSize      MessageType = "set_board_size"
+	MsgSetTeams          MessageType = "set_teams"
+	MsgSetTeamSize       MessageType = "set_team_size"
+	MsgSetEventRetention MessageType = "set_event_retention"
+	MsgGetEventLog       MessageType = "get_event_log"
+	MsgUndo              MessageType = "undo"
+	MsgRedo              MessageType = "redo"
+	MsgGetReplay         MessageType = "get_replay"
+	MsgSetTimeLimit      MessageType = "set_time_limit"
+	MsgSetCountdown      MessageType = "set_countdown"
+	MsgSetHiddenBoard    MessageType = "set_hidden_board"
+	MsgSetGoalPool       MessageType = "set_goal_pool"
+	MsgGetGoalPool       MessageType = "get_goal_pool"
+	MsgGenerateBoard     MessageType = "generate_board"
+	MsgGetRules          MessageType = "get_rules"
+	MsgSetLinesToWin     MessageType = "set_lines_to_win"
+	MsgSetSeries         MessageType = "set_series"
+	MsgSetVerifyClaims   MessageType = "set_verify_claims"
+	MsgConfirmClaim      MessageType = "confirm_claim"
+	MsgRejectClaim       MessageType = "reject_claim"
+	MsgDisputeCell       MessageType = "dispute_cell"
+	MsgResolveDispute    MessageType = "resolve_dispute"
+	MsgRaiseProgress     MessageType = "raise_progress"
+	MsgLowerProgress     MessageType = "lower_progress"
+	MsgSetIntent         MessageType = "set_intent"
+	MsgSetPublicIntents  MessageType = "set_public_intents"
+	MsgSetCellLock       MessageType = "set_cell_lock"
 
 	// Stream token operations
 	MsgCreateStreamToken MessageType = "create_stream_token"
@@ -43,6 +70,10 @@ const (
 	MsgError       MessageType = "error"
 	MsgJoined      MessageType = "joined"
 	MsgLeft        MessageType = "left"
+	MsgEventLog    MessageType = "event_log"
+	MsgReplayState MessageType = "replay_state"
+	MsgGoalPool    MessageType = "goal_pool"
+	MsgRules       MessageType = "rules"
 )
 
 // Message is the base message structure
@@ -76,113 +107,450 @@ type JoinRoomPayload struct {
 type SetRolePayload struct {
 	TargetUserID string `json:"target_user_id"`
 	Role         string `json:"role"`
-	PlayerColor  string `json:"player_color,omitempty"`
+	Team         string `json:"team,omitempty"`
 }
 
 // MarkCellPayload represents the payload for marking a cell
 type MarkCellPayload struct {
-	Row   int    `json:"row"`
-	Col   int    `json:"col"`
-	Color string `json:"color"`
+	Row  int    `json:"row"`
+	Col  int    `json:"col"`
+	Team string `json:"team"`
 }
 
-// ClearCellMarkPayload represents the payload for clearing a specific color mark
+// ClearCellMarkPayload represents the payload for clearing a specific team's mark
 type ClearCellMarkPayload struct {
-	Row   int    `json:"row"`
-	Col   int    `json:"col"`
-	Color string `json:"color"`
+	Row  int    `json:"row"`
+	Col  int    `json:"col"`
+	Team string `json:"team"`
+}
+
+// ProgressPayload represents the payload for raising or lowering a team's counter on a cell by one
+type ProgressPayload struct {
+	Row  int    `json:"row"`
+	Col  int    `json:"col"`
+	Team string `json:"team"`
+}
+
+// ClaimPayload represents the payload for confirming or rejecting a team's pending claim
+type ClaimPayload struct {
+	Row  int    `json:"row"`
+	Col  int    `json:"col"`
+	Team string `json:"team"`
+}
+
+// DisputeCellPayload represents the payload for disputing another team's mark
+type DisputeCellPayload struct {
+	Row    int    `json:"row"`
+	Col    int    `json:"col"`
+	Team   string `json:"team,omitempty"` // Team whose mark is disputed, the cell's first marker if empty
+	Reason string `json:"reason"`
+}
+
+// ResolveDisputePayload represents the payload for the referee resolving a dispute
+type ResolveDisputePayload struct {
+	ID         int    `json:"id"`
+	Resolution string `json:"resolution"` // "upheld" keeps the mark, "reverted" removes it
+}
+
+// SetVerifyClaimsPayload represents the payload for turning claim verification on or off
+type SetVerifyClaimsPayload struct {
+	Verify bool `json:"verify"`
 }
 
 // SetRulePayload represents the payload for setting game rule
 type SetRulePayload struct {
-	Rule        string             `json:"rule"`
-	PhaseConfig PhaseConfigPayload `json:"phase_config,omitempty"`
+	Rule          string             `json:"rule"`
+	PhaseConfig   PhaseConfigPayload `json:"phase_config,omitempty"`
+	WinPatterns   []string           `json:"win_patterns,omitempty"`   // Normal rule: "line", "x", "plus", "corners", "frame", "custom"; kept if omitted
+	CustomPattern [][]bool           `json:"custom_pattern,omitempty"` // Cell mask for the "custom" pattern
+	PointsTarget  *int               `json:"points_target,omitempty"`  // Points rule: score that wins, 0 for none; kept if omitted
+	FreeCenter    *bool              `json:"free_center,omitempty"`    // Center cell is a free space once the game starts; kept if omitted
+	Handicaps     []HandicapPayload  `json:"handicaps,omitempty"`      // Pre-marked cells, score offsets and start delays per team; kept if omitted, [] clears
+}
+
+// HandicapPayload represents a team's handicap in a handicap match
+type HandicapPayload struct {
+	Team        string   `json:"team"`
+	Cells       [][2]int `json:"cells,omitempty"`        // [row, col], marked for the team when the game starts
+	ScoreOffset int      `json:"score_offset,omitempty"` // Added to the team's score
+	StartDelay  int      `json:"start_delay,omitempty"`  // Seconds after the start before the team can mark
+}
+
+// RulesPayload lists the rules set_rule accepts
+type RulesPayload struct {
+	Rules []RuleInfoPayload `json:"rules"`
+}
+
+// RuleInfoPayload describes a rule and the config fields it reads
+type RuleInfoPayload struct {
+	Name   string               `json:"name"`
+	Config []ConfigFieldPayload `json:"config,omitempty"`
+}
+
+// ConfigFieldPayload describes a rule config field
+type ConfigFieldPayload struct {
+	Key         string `json:"key"`
+	Type        string `json:"type"` // "int", "int_per_row" (one value per board row) or "phases"
+	Min         int    `json:"min"`
+	Description string `json:"description"`
 }
 
 // SetCellTextPayload represents the payload for setting cell text
 type SetCellTextPayload struct {
-	Row   int      `json:"row,omitempty"`
-	Col   int      `json:"col,omitempty"`
-	Text  string   `json:"text,omitempty"`
-	Texts []string `json:"texts,omitempty"`
+	Row         int      `json:"row,omitempty"`
+	Col         int      `json:"col,omitempty"`
+	Text        string   `json:"text,omitempty"`
+	Texts       []string `json:"texts,omitempty"`
+	Points      *int     `json:"points,omitempty"`       // Point value of the single cell, kept if omitted
+	CellPoints  []int    `json:"cell_points,omitempty"`  // Point values of all cells in row-major order
+	Target      *int     `json:"target,omitempty"`       // Target count of the single cell, kept if omitted
+	CellTargets []int    `json:"cell_targets,omitempty"` // Target counts of all cells in row-major order
+}
+
+// SetBoardSizePayload represents the payload for setting the board size
+type SetBoardSizePayload struct {
+	Size int `json:"size"`
+}
+
+// SetTeamsPayload represents the payload for setting the team list
+type SetTeamsPayload struct {
+	Teams []TeamPayload `json:"teams"`
+}
+
+// SetTeamSizePayload represents the payload for setting the per-team player limit
+type SetTeamSizePayload struct {
+	MaxTeamSize int `json:"max_team_size"`
+}
+
+// SetEventRetentionPayload represents the payload for setting how many games the event log keeps
+type SetEventRetentionPayload struct {
+	Games int `json:"games"` // 0 keeps all
+}
+
+// GetEventLogPayload represents the payload for requesting the event log
+// Only events with a sequence number greater than Since are returned
+type GetEventLogPayload struct {
+	Since int `json:"since,omitempty"`
+}
+
+// GetReplayPayload represents the payload for requesting a replayed game state
+// Time (Unix milliseconds) takes precedence over Seq; with neither the latest state is rebuilt
+type GetReplayPayload struct {
+	Seq  int   `json:"seq,omitempty"`
+	Time int64 `json:"time,omitempty"`
+}
+
+// SetTimeLimitPayload represents the payload for setting the game time limit
+type SetTimeLimitPayload struct {
+	TimeLimit int `json:"time_limit"` // Seconds, 0 removes the limit
+}
+
+// SetLinesToWinPayload represents the payload for setting the lines needed to win in normal rule
+type SetLinesToWinPayload struct {
+	Lines int `json:"lines"`
+}
+
+// SetCountdownPayload represents the payload for setting the start countdown
+type SetCountdownPayload struct {
+	Countdown int `json:"countdown"` // Seconds, 0 starts the game at once
+}
+
+// SetSeriesPayload represents the payload for starting a best-of-N series, 0 ends it
+type SetSeriesPayload struct {
+	BestOf int `json:"best_of"`
+}
+
+// SetIntentPayload represents the payload for showing or hiding the player's intent marker on a cell
+type SetIntentPayload struct {
+	Row    int  `json:"row"`
+	Col    int  `json:"col"`
+	Active bool `json:"active"`
+}
+
+// SetCellLockPayload represents the payload for locking ("locked"), voiding ("void") or reopening ("") a cell
+type SetCellLockPayload struct {
+	Row  int    `json:"row"`
+	Col  int    `json:"col"`
+	Lock string `json:"lock"`
+}
+
+// SetPublicIntentsPayload represents the payload for showing intent markers to everyone
+type SetPublicIntentsPayload struct {
+	Public bool `json:"public"`
+}
+
+// SetHiddenBoardPayload represents the payload for hiding the board until the game starts
+type SetHiddenBoardPayload struct {
+	Hidden bool `json:"hidden"`
+}
+
+// GoalPayload represents a goal of a goal pool
+type GoalPayload struct {
+	Text       string   `json:"text"`
+	Difficulty int      `json:"difficulty"`       // Tier, 1 is the easiest
+	Group      string   `json:"group,omitempty"`  // At most one goal of a group goes on a board
+	Tags       []string `json:"tags,omitempty"`   // Goals sharing a tag never share a line
+	Target     int      `json:"target,omitempty"` // Count to reach for "collect N" goals, 0 for none
+}
+
+// SetGoalPoolPayload represents the payload for setting the room's goal pool
+type SetGoalPoolPayload struct {
+	Goals []GoalPayload `json:"goals"` // Empty removes the pool
+}
+
+// GoalPoolPayload represents the room's goal pool
+type GoalPoolPayload struct {
+	Goals []GoalPayload `json:"goals"`
+}
+
+// GenerateBoardPayload represents the payload for generating the board from the goal pool
+type GenerateBoardPayload struct {
+	Seed *int64 `json:"seed,omitempty"` // Random if omitted
 }
 
 // SettlePayload represents the payload for settlement (phase rule)
 type SettlePayload struct {
-	Player string `json:"player"`
+	Team string `json:"team"`
 }
 
 // PhaseConfigPayload represents phase rule configuration
+// In set_rule, omitted fields keep their defaults, so an explicit 0 can be told apart
 type PhaseConfigPayload struct {
 	RowScores        []int `json:"row_scores"`
 	SecondHalfScores []int `json:"second_half_scores"`
-	CellsPerRow      int   `json:"cells_per_row"`
-	UnlockThreshold  int   `json:"unlock_threshold"`
-	BingoBonus       int   `json:"bingo_bonus"`
-	FinalBonus       int   `json:"final_bonus"`
+	CellsPerRow      *int  `json:"cells_per_row,omitempty"`
+	UnlockThreshold  *int  `json:"unlock_threshold,omitempty"`
+	BingoBonus       *int  `json:"bingo_bonus,omitempty"`
+	FinalBonus       *int  `json:"final_bonus,omitempty"`
+
+	// Phases as cell groups instead of one per row, team_states then count marks per phase
+	Phases []PhasePayload `json:"phases,omitempty"`
+}
+
+// PhasePayload represents a phase made of any group of cells
+type PhasePayload struct {
+	Cells           [][2]int `json:"cells"` // Cells as [row, col]
+	Score           int      `json:"score"`
+	LaterScore      int      `json:"later_score"`
+	Limit           int      `json:"limit"`
+	UnlockThreshold int      `json:"unlock_threshold"`
 }
 
 // StateUpdatePayload represents the full game state
 type StateUpdatePayload struct {
-	Room        RoomPayload   `json:"room"`
-	Game        GamePayload   `json:"game"`
-	Users       []UserPayload `json:"users"`
-	CurrentUser string        `json:"current_user"`
+	Room        RoomPayload      `json:"room"`
+	Game        GamePayload      `json:"game"`
+	Series      *SeriesPayload   `json:"series,omitempty"`        // Set while the room plays a series
+	Disputes    []DisputePayload `json:"disputes,omitempty"`      // Disputes of the current game, open and resolved
+	Queue       []DisputePayload `json:"dispute_queue,omitempty"` // Open disputes waiting for the referee, oldest first
+	Users       []UserPayload    `json:"users"`
+	CurrentUser string           `json:"current_user"`
+}
+
+// DisputePayload represents a dispute of a mark, times are Unix milliseconds
+type DisputePayload struct {
+	ID           int    `json:"id"`
+	Time         int64  `json:"time"`
+	Row          int    `json:"row"`
+	Col          int    `json:"col"`
+	Team         string `json:"team"` // Team whose mark is disputed
+	By           string `json:"by"`   // Team raising the dispute
+	PlayerName   string `json:"player_name"`
+	Reason       string `json:"reason"`
+	Status       string `json:"status"` // "open", "upheld" or "reverted"
+	ResolverName string `json:"resolver_name,omitempty"`
+	ResolvedAt   int64  `json:"resolved_at,omitempty"`
+}
+
+// SeriesPayload represents a best-of-N series, counting the current game once it has finished
+type SeriesPayload struct {
+	BestOf     int                `json:"best_of"`
+	WinsNeeded int                `json:"wins_needed"`
+	Wins       []TeamScorePayload `json:"wins"`               // Games won per team
+	Games      []WinnerPayload    `json:"games,omitempty"`    // Result of each game played
+	Disputes   [][]DisputePayload `json:"disputes,omitempty"` // Disputes raised in each game, in the order of Games
+	Over       bool               `json:"over,omitempty"`     // Whether the series is decided
+	Winner     string             `json:"winner,omitempty"`   // Series winner once over, "none" on a tie
 }
 
 // RoomPayload represents room information
 type RoomPayload struct {
-	ID          string `json:"id"`
-	Name        string `json:"name"`
-	OwnerID     string `json:"owner_id"`
-	HasPassword bool   `json:"has_password"`
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	OwnerID        string `json:"owner_id"`
+	HasPassword    bool   `json:"has_password"`
+	MaxTeamSize    int    `json:"max_team_size,omitempty"`
+	EventRetention int    `json:"event_retention,omitempty"` // Games the event log keeps, 0 keeps all
+	HiddenBoard    bool   `json:"hidden_board,omitempty"`
+	PublicIntents  bool   `json:"public_intents,omitempty"` // Intent markers are shown to everyone
+	CanUndo        bool   `json:"can_undo,omitempty"`
+	CanRedo        bool   `json:"can_redo,omitempty"`
+	GoalPoolSize   int    `json:"goal_pool_size,omitempty"`
 }
 
 // GamePayload represents game state
 type GamePayload struct {
-	Board           BoardPayload       `json:"board"`
-	Rule            string             `json:"rule"`
-	PhaseConfig     PhaseConfigPayload `json:"phase_config,omitempty"`
-	Status          string             `json:"status"`
-	Winner          *WinnerPayload     `json:"winner,omitempty"`
-	RedRowMarks     []int              `json:"red_row_marks,omitempty"`
-	BlueRowMarks    []int              `json:"blue_row_marks,omitempty"`
-	RedUnlockedRow  int                `json:"red_unlocked_row,omitempty"`
-	BlueUnlockedRow int                `json:"blue_unlocked_row,omitempty"`
-	BingoAchiever   string             `json:"bingo_achiever,omitempty"`
-	BingoLine       int                `json:"bingo_line,omitempty"`
-	RedSettled      bool               `json:"red_settled,omitempty"`
-	BlueSettled     bool               `json:"blue_settled,omitempty"`
-	FirstSettler    string             `json:"first_settler,omitempty"`
+	Board          BoardPayload       `json:"board"`
+	Seed           *int64             `json:"seed,omitempty"` // Set when the board was generated from the goal pool
+	Rule           string             `json:"rule"`
+	PhaseConfig    PhaseConfigPayload `json:"phase_config,omitempty"`
+	Status         string             `json:"status"`
+	Winner         *WinnerPayload     `json:"winner,omitempty"`
+	Teams          []TeamPayload      `json:"teams"`
+	TeamStates     []TeamStatePayload `json:"team_states,omitempty"`
+	BingoAchiever  string             `json:"bingo_achiever,omitempty"`
+	BingoLine      int                `json:"bingo_line,omitempty"`
+	FirstSettler   string             `json:"first_settler,omitempty"`
+	LinesToWin     int                `json:"lines_to_win,omitempty"`
+	PointsTarget   int                `json:"points_target,omitempty"`
+	VerifyClaims   bool               `json:"verify_claims,omitempty"` // Player marks are claims until a referee confirms them
+	WinPatterns    []string           `json:"win_patterns,omitempty"`
+	CustomPattern  [][]bool           `json:"custom_pattern,omitempty"`
+	FreeCenter     bool               `json:"free_center,omitempty"`
+	Handicaps      []HandicapPayload  `json:"handicaps,omitempty"`
+	CompletedLines []TeamLinesPayload `json:"completed_lines,omitempty"`
+	Clock          ClockPayload       `json:"clock"`
+}
+
+// ClockPayload represents the server game clock, times are Unix milliseconds
+// Clients should measure time against ServerTime rather than their own clock
+type ClockPayload struct {
+	ServerTime int64 `json:"server_time"` // When this payload was built
+	StartTime  int64 `json:"start_time,omitempty"`
+	EndTime    int64 `json:"end_time,omitempty"`
+	Deadline   int64 `json:"deadline,omitempty"`
+	TimeLimit  int   `json:"time_limit,omitempty"`  // Seconds, 0 means no limit
+	Countdown  int   `json:"countdown,omitempty"`   // Seconds between start and reveal
+	RevealTime int64 `json:"reveal_time,omitempty"` // Set during the countdown: when the board is revealed
+}
+
+// TeamPayload represents a team
+type TeamPayload struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// TeamLinesPayload lists the lines a team has marked entirely
+// Line indexes: 0..N-1 rows, N..2N-1 columns, 2N diagonal \, 2N+1 diagonal /
+type TeamLinesPayload struct {
+	Team  string `json:"team"`
+	Lines []int  `json:"lines"`
+}
+
+// TeamStatePayload represents per-team progress (phase rule)
+type TeamStatePayload struct {
+	Team        string `json:"team"`
+	RowMarks    []int  `json:"row_marks"`
+	UnlockedRow int    `json:"unlocked_row"`
+	Settled     bool   `json:"settled"`
 }
 
 // BoardPayload represents the board state
 type BoardPayload struct {
+	Size  int             `json:"size"`
 	Cells [][]CellPayload `json:"cells"`
 }
 
 // CellPayload represents a cell state
+// MarkedBy and SecondMark are the first two entries of Marks
 type CellPayload struct {
-	MarkedBy   string `json:"marked_by"`
-	SecondMark string `json:"second_mark,omitempty"`
-	Times      int    `json:"times"`
-	Text       string `json:"text"`
+	MarkedBy   string             `json:"marked_by"`
+	SecondMark string             `json:"second_mark,omitempty"`
+	Marks      []MarkPayload      `json:"marks,omitempty"`
+	Claims     []MarkPayload      `json:"claims,omitempty"`  // Pending claims awaiting the referee
+	Intents    []MarkPayload      `json:"intents,omitempty"` // Players working on the cell, only those the receiver may see
+	Times      int                `json:"times"`
+	Text       string             `json:"text"`
+	Lock       string             `json:"lock,omitempty"`     // Referee lock: "locked" or "void", a void cell is a free space
+	Points     int                `json:"points,omitempty"`   // Point value in points rule, 0 means 1
+	Target     int                `json:"target,omitempty"`   // Count to reach, 0 for none
+	Progress   []TeamCountPayload `json:"progress,omitempty"` // Teams' counters towards Target, in team order
+}
+
+// TeamCountPayload represents a team's counter on a cell
+type TeamCountPayload struct {
+	Team  string `json:"team"`
+	Count int    `json:"count"`
+}
+
+// MarkPayload represents a single team's mark on a cell
+type MarkPayload struct {
+	Team       string `json:"team"`
+	PlayerID   string `json:"player_id,omitempty"`
+	PlayerName string `json:"player_name,omitempty"`
 }
 
 // UserPayload represents user information
 type UserPayload struct {
-	ID          string `json:"id"`
-	Name        string `json:"name"`
-	Role        string `json:"role"`
-	PlayerColor string `json:"player_color"`
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Role string `json:"role"`
+	Team string `json:"team"`
 }
 
 // WinnerPayload represents winner information
 type WinnerPayload struct {
-	Winner    string `json:"winner"`
-	Reason    string `json:"reason"`
-	RedScore  int    `json:"red_score"`
-	BlueScore int    `json:"blue_score"`
+	Winner  string             `json:"winner"`
+	Reason  string             `json:"reason"`
+	Scores  []TeamScorePayload `json:"scores"`
+	Pattern string             `json:"pattern,omitempty"` // Win pattern completed in normal rule
+	Cells   [][2]int           `json:"cells,omitempty"`   // Cells of that pattern as [row, col]
+
+	// Team handicaps the game was played with, Scores include the score offsets
+	Handicaps []HandicapPayload `json:"handicaps,omitempty"`
+}
+
+// TeamScorePayload represents a team's final score
+type TeamScorePayload struct {
+	Team  string `json:"team"`
+	Score int    `json:"score"`
+}
+
+// EventLogPayload represents a part of the room's event log
+type EventLogPayload struct {
+	Events  []EventPayload `json:"events"`
+	LastSeq int            `json:"last_seq"`
+}
+
+// EventPayload represents a single logged game action
+// Row and Col are omitted for actions that do not target a cell
+type EventPayload struct {
+	Seq       int    `json:"seq"`
+	Time      int64  `json:"time"` // Unix milliseconds
+	ActorID   string `json:"actor_id"`
+	ActorName string `json:"actor_name"`
+	Action    string `json:"action"`
+	Row       *int   `json:"row,omitempty"`
+	Col       *int   `json:"col,omitempty"`
+	Team      string `json:"team,omitempty"`
+}
+
+// ReplayStatePayload represents a game state rebuilt from the event log
+type ReplayStatePayload struct {
+	Seq     int         `json:"seq,omitempty"`
+	Time    int64       `json:"time,omitempty"`
+	Game    GamePayload `json:"game"`
+	Partial bool        `json:"partial,omitempty"` // The game had not finished at that point
+}
+
+// ReplayPayload represents a whole match as a list of frames, served to the overlay
+type ReplayPayload struct {
+	Room   RoomPayload          `json:"room"`
+	Users  []UserPayload        `json:"users"`
+	Frames []ReplayFramePayload `json:"frames"`
+
+	// The frames end before the game finished, as it is still being played or was reset
+	Partial bool `json:"partial,omitempty"`
+}
+
+// ReplayFramePayload represents the game state right after a logged event
+type ReplayFramePayload struct {
+	Seq       int         `json:"seq"`
+	Time      int64       `json:"time"` // Unix milliseconds
+	Action    string      `json:"action"`
+	ActorName string      `json:"actor_name"`
+	Game      GamePayload `json:"game"`
 }
 
 // RoomListPayload represents a list of rooms
@@ -194,6 +562,8 @@ type RoomListPayload struct {
 type ErrorPayload struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
+	Field   string `json:"field,omitempty"`  // Rejected field, e.g. "phase_config.cells_per_row"
+	Reason  string `json:"reason,omitempty"` // Field error code, e.g. "too_small"
 }
 
 // StreamTokenPayload represents a stream token response